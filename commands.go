@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"GoDash/internal/config"
+	calendarwidget "GoDash/widgets/calendar"
+	"GoDash/widgets/notes"
+)
+
+// runSubcommand executes a non-interactive command such as "godash export".
+// It reports whether args named a known subcommand.
+func runSubcommand(args []string) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+
+	switch args[0] {
+	case "export":
+		return true, runExportNotes(args[1:])
+	case "import":
+		return true, runImportNotes(args[1:])
+	case "calendars":
+		return true, runListCalendars(args[1:])
+	case "signout":
+		return true, runSignOut(args[1:])
+	case "agenda":
+		return true, runAgenda(args[1:])
+	}
+	return false, nil
+}
+
+// runExportNotes converts the notes directory to HTML.
+func runExportNotes(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	outDir := fs.String("o", "godash-notes", "output directory")
+	site := fs.Bool("site", false, "also write an index page and tag pages")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: godash export [-site] [-o dir]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	count, err := notes.ExportHTML(*outDir, notes.ExportOptions{Site: *site})
+	if err != nil {
+		return fmt.Errorf("could not export notes: %w", err)
+	}
+	fmt.Printf("Exported %d notes to %s\n", count, *outDir)
+	return nil
+}
+
+// runImportNotes imports an Obsidian vault, a Joplin export or a markdown
+// folder into the notes directory and prints what was done.
+func runImportNotes(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "auto", "source format: auto, obsidian, joplin or markdown")
+	into := fs.String("into", "", "folder inside the notes directory to import into")
+	dryRun := fs.Bool("dry-run", false, "only report what would be imported")
+	overwrite := fs.Bool("overwrite", false, "replace notes that already exist")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: godash import [-dry-run] [-format f] [-into folder] [-overwrite] <vault, folder or .jex file>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	report, err := notes.ImportNotes(fs.Arg(0), notes.ImportOptions{
+		Format:    notes.ImportFormat(*format),
+		Into:      *into,
+		DryRun:    *dryRun,
+		Overwrite: *overwrite,
+	})
+	for _, e := range report.Entries {
+		switch {
+		case e.Skipped:
+			fmt.Printf("  skip    %s (%s)\n", e.Source, e.Reason)
+		case e.Attachment:
+			fmt.Printf("  attach  %s\n", e.Target)
+		default:
+			fmt.Printf("  create  %s\n", e.Target)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("could not import notes: %w", err)
+	}
+
+	created, attachments, skipped := report.Counts()
+	verb := "Imported"
+	if *dryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("%s %d notes and %d attachments (%s), skipped %d\n", verb, created, attachments, report.Format, skipped)
+	return nil
+}
+
+// runListCalendars prints the calendars of the configured Google accounts,
+// whose names or IDs can be listed in a calendar's "calendars" setting.
+func runListCalendars(args []string) error {
+	fs := flag.NewFlagSet("calendars", flag.ExitOnError)
+	account := fs.String("account", "", "only list the calendars of this account")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: godash calendars [-account name]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return fmt.Errorf("could not load settings: %w", err)
+	}
+	accounts := calendarwidget.GoogleAccounts(settings.Calendars)
+	if *account != "" {
+		accounts = []string{*account}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for i, acc := range accounts {
+		if i > 0 {
+			fmt.Println()
+		}
+		label := acc
+		if label == "" {
+			label = "default"
+		}
+		fmt.Printf("Google account %q:\n", label)
+
+		calendars, err := calendarwidget.ListGoogleCalendars(ctx, acc)
+		if errors.Is(err, calendarwidget.ErrAuthRequired) {
+			fmt.Println("  not authorized yet, start GoDash to connect it")
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, cal := range calendars {
+			var flags string
+			if cal.Primary {
+				flags += " (primary)"
+			}
+			if cal.ReadOnly {
+				flags += " (read-only)"
+			}
+			fmt.Printf("  %-7s  %s%s\n           %s\n", cal.Color, cal.Name, flags, cal.ID)
+		}
+	}
+	return nil
+}
+
+// runSignOut revokes GoDash's access to a Google account and deletes its
+// token, so the account is authorized again on the next start.
+func runSignOut(args []string) error {
+	fs := flag.NewFlagSet("signout", flag.ExitOnError)
+	account := fs.String("account", "", "account to sign out of instead of the default one")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: godash signout [-account name]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	label := *account
+	if label == "" {
+		label = "default"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	err := calendarwidget.SignOut(ctx, *account)
+	if errors.Is(err, calendarwidget.ErrAuthRequired) {
+		fmt.Printf("Not signed in to Google account %q\n", label)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Signed out of Google account %q\n", label)
+	return nil
+}
+
+// runAgenda exports the events of a range of days, fetching the months that
+// aren't cached yet, as a text or markdown agenda or as an .ics file.
+func runAgenda(args []string) error {
+	fs := flag.NewFlagSet("agenda", flag.ExitOnError)
+	from := fs.String("from", "", "first day as 2006-01-02 (default today)")
+	to := fs.String("to", "", "last day as 2006-01-02 (default the -days after -from)")
+	days := fs.Int("days", 1, "number of days when -to isn't given")
+	format := fs.String("format", "text", "output format: text, markdown or ics")
+	out := fs.String("o", "", "file to write instead of the standard output")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: godash agenda [-from date] [-to date | -days n] [-format text|markdown|ics] [-o file]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if *from != "" {
+		t, err := time.ParseInLocation("2006-01-02", *from, time.Local)
+		if err != nil {
+			return fmt.Errorf("-from %q is not a date like 2006-01-02", *from)
+		}
+		start = t
+	}
+	end := start.AddDate(0, 0, max(*days, 1))
+	if *to != "" {
+		t, err := time.ParseInLocation("2006-01-02", *to, time.Local)
+		if err != nil {
+			return fmt.Errorf("-to %q is not a date like 2006-01-02", *to)
+		}
+		if t.Before(start) {
+			return fmt.Errorf("-to is before -from")
+		}
+		end = t.AddDate(0, 0, 1)
+	}
+	if *format != "text" && *format != "markdown" && *format != "ics" {
+		return fmt.Errorf("unknown format %q, use text, markdown or ics", *format)
+	}
+
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return fmt.Errorf("could not load settings: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	events, err := calendarwidget.LoadEvents(ctx, settings.Calendars, start, end)
+	if errors.Is(err, calendarwidget.ErrAuthRequired) {
+		return fmt.Errorf("the calendar isn't authorized yet, start GoDash to connect it")
+	}
+	if err != nil {
+		return err
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("could not create %s: %w", *out, err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if *format == "ics" {
+		err = calendarwidget.ExportICS(w, events)
+	} else {
+		err = calendarwidget.ExportAgenda(w, events, start, end, *format == "markdown")
+	}
+	if err != nil {
+		return fmt.Errorf("could not export events: %w", err)
+	}
+	if *out != "" {
+		fmt.Printf("Exported %d events to %s\n", len(events), *out)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+
+	calendarwidget "GoDash/widgets/calendar"
+)
+
+// eventDetail holds the state of stateEventDetail.
+type eventDetail struct {
+	event    calendarwidget.Event
+	viewport viewport.Model
+	errMsg   string
+}
+
+var (
+	detailLabelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#5c6370"))
+	responseStyles   = map[string]lipgloss.Style{
+		"accepted":  lipgloss.NewStyle().Foreground(lipgloss.Color("#98c379")),
+		"declined":  lipgloss.NewStyle().Foreground(lipgloss.Color("#e06c75")),
+		"tentative": lipgloss.NewStyle().Foreground(lipgloss.Color("#e5c07b")),
+	}
+)
+
+// eventDetailSize returns the size of the event popup's scrolling area.
+func (m *model) eventDetailSize() (int, int) {
+	width := min(80, m.width*4/5)
+	height := m.height * 7 / 10
+	// Border, padding, title and instructions.
+	return width - 6, max(3, height-8)
+}
+
+// openEventDetail switches to the popup showing event.
+func (m *model) openEventDetail(event calendarwidget.Event) {
+	width, height := m.eventDetailSize()
+	content := m.renderEventDetail(event, width)
+	vp := viewport.New(width, min(height, lipgloss.Height(content)))
+	vp.SetContent(content)
+	m.eventDetail = eventDetail{event: event, viewport: vp}
+	m.state = stateEventDetail
+	m.updateKeybindings()
+}
+
+// renderEventDetail lists when and where the event is, its guests and its
+// description rendered as Markdown.
+func (m *model) renderEventDetail(event calendarwidget.Event, width int) string {
+	var lines []string
+	field := func(label, value string) {
+		if value != "" {
+			lines = append(lines, detailLabelStyle.Render(fmt.Sprintf("%-9s", label))+value)
+		}
+	}
+	field("When", event.TimeRange())
+	field("Where", event.Location)
+	field("Calendar", event.Calendar)
+	field("Call", event.ConferenceURL)
+
+	if len(event.Attendees) > 0 {
+		counts := make(map[string]int)
+		for _, a := range event.Attendees {
+			counts[a.Status]++
+		}
+		summary := fmt.Sprintf("%d guests: %d yes", len(event.Attendees), counts["accepted"])
+		if n := counts["tentative"]; n > 0 {
+			summary += fmt.Sprintf(", %d maybe", n)
+		}
+		if n := counts["declined"]; n > 0 {
+			summary += fmt.Sprintf(", %d no", n)
+		}
+		if n := counts["needsAction"] + counts[""]; n > 0 {
+			summary += fmt.Sprintf(", %d awaiting", n)
+		}
+		lines = append(lines, "", detailLabelStyle.Render(summary))
+
+		for _, a := range event.Attendees {
+			name := a.Name
+			if name == "" {
+				name = a.Email
+			}
+			var notes []string
+			if a.Organizer {
+				notes = append(notes, "organizer")
+			}
+			if a.Optional {
+				notes = append(notes, "optional")
+			}
+			if a.Self {
+				notes = append(notes, "you")
+			}
+			if len(notes) > 0 {
+				name += detailLabelStyle.Render(" (" + strings.Join(notes, ", ") + ")")
+			}
+			icon := calendarwidget.ResponseIcon(a.Status)
+			if style, ok := responseStyles[a.Status]; ok {
+				icon = style.Render(icon)
+			}
+			lines = append(lines, "  "+icon+" "+name)
+		}
+	}
+
+	if description := event.DescriptionMarkdown(); description != "" {
+		rendered := description
+		renderer, err := glamour.NewTermRenderer(
+			glamour.WithStandardStyle(m.markdownStyle),
+			glamour.WithWordWrap(width-2),
+		)
+		if err == nil {
+			if out, err := renderer.Render(description); err == nil {
+				rendered = strings.Trim(out, "\n")
+			}
+		}
+		lines = append(lines, "", rendered)
+	}
+	return lipgloss.NewStyle().Width(width).Render(strings.Join(lines, "\n"))
+}
+
+// closeEventDetail returns to the dashboard.
+func (m *model) closeEventDetail() {
+	m.eventDetail = eventDetail{}
+	m.state = stateDashboard
+	m.updateKeybindings()
+}
+
+// --- UPDATE: EVENT DETAIL ---
+func (m model) updateEventDetail(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if _, ok := msg.(tea.WindowSizeMsg); ok {
+		offset := m.eventDetail.viewport.YOffset
+		m.openEventDetail(m.eventDetail.event)
+		m.eventDetail.viewport.SetYOffset(offset)
+	}
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		// Keep the calendar up to date, e.g. with changes still being saved.
+		m.calendar, cmd = m.calendar.Update(msg, false)
+		return m, cmd
+	}
+
+	event := m.eventDetail.event
+	m.eventDetail.errMsg = ""
+	switch {
+	case key.Matches(keyMsg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("esc", "q", "enter"))):
+		m.closeEventDetail()
+		return m, nil
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("o"))):
+		if event.URL == "" {
+			m.eventDetail.errMsg = "This event has no web page"
+		} else if err := openURLInBrowser(event.URL); err != nil {
+			m.eventDetail.errMsg = err.Error()
+		}
+		return m, nil
+	case key.Matches(keyMsg, key.NewBinding(key.WithKeys("c"))):
+		if event.ConferenceURL == "" {
+			m.eventDetail.errMsg = "This event has no video call"
+		} else if err := openURLInBrowser(event.ConferenceURL); err != nil {
+			m.eventDetail.errMsg = err.Error()
+		}
+		return m, nil
+	}
+
+	m.eventDetail.viewport, cmd = m.eventDetail.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewEventDetail() string {
+	event := m.eventDetail.event
+	title := event.Summary
+	if title == "" {
+		title = "(No title)"
+	}
+
+	parts := []string{
+		helpTitleStyle.Render(title),
+		"",
+		m.eventDetail.viewport.View(),
+	}
+	if m.eventDetail.errMsg != "" {
+		parts = append(parts, "", redText.Render(m.eventDetail.errMsg))
+	}
+
+	var instructions []string
+	if event.URL != "" {
+		instructions = append(instructions, "o open in browser")
+	}
+	if event.ConferenceURL != "" {
+		instructions = append(instructions, "c join call")
+	}
+	if m.eventDetail.viewport.TotalLineCount() > m.eventDetail.viewport.Height {
+		instructions = append(instructions, "↑/↓ scroll")
+	}
+	instructions = append(instructions, "esc close")
+	parts = append(parts, "", lipgloss.NewStyle().Foreground(lipgloss.Color("#7c7c7c")).Render(strings.Join(instructions, " • ")))
+
+	content := lipgloss.JoinVertical(lipgloss.Left, parts...)
+	width, _ := m.eventDetailSize()
+	dialogBox := helpBoxStyle.Width(width + 4).Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, dialogBox)
+}
@@ -96,6 +96,16 @@ func GetNotesDir() (string, error) {
 	return filepath.Join(dataDir, "notes"), nil
 }
 
+// GetDraftsDir returns the path to the directory holding autosaved note drafts.
+// Drafts are recoverable scratch data, so they live in the cache directory.
+func GetDraftsDir() (string, error) {
+	cacheDir, err := GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "drafts"), nil
+}
+
 // GetTodoPath returns the full path to the todo list file.
 func GetTodoPath() (string, error) {
 	dataDir, err := GetDataDir()
@@ -109,9 +119,53 @@ func GetTodoPath() (string, error) {
 type Settings struct {
 	Location           string `json:"location"`
 	DefaultNotesCreated bool   `json:"default_notes_created"`
+	// Calendars lists the calendars shown in the calendar widget. When empty,
+	// the Google primary calendar is used.
+	Calendars []CalendarSource `json:"calendars,omitempty"`
+	// Reminders configures the reminders shown before events.
+	Reminders *ReminderSettings `json:"reminders,omitempty"`
+	// Holidays configures the holidays and personal dates marked in the
+	// calendar.
+	Holidays *HolidaySettings `json:"holidays,omitempty"`
+}
+
+// HolidaySettings chooses the country whose public holidays are shown and
+// the file listing birthdays and anniversaries.
+type HolidaySettings struct {
+	Country   string `json:"country,omitempty"`    // ISO 3166 code such as "GR"
+	DatesFile string `json:"dates_file,omitempty"` // default dates.txt in the config directory
+}
+
+// ReminderSettings configures event reminders. Events without reminders of
+// their own are announced LeadMinutes before they start.
+type ReminderSettings struct {
+	Disabled      bool `json:"disabled,omitempty"`
+	LeadMinutes   int  `json:"lead_minutes,omitempty"`   // default 10
+	SnoozeMinutes int  `json:"snooze_minutes,omitempty"` // default 5
+	// Desktop also sends desktop notifications, which need a notification
+	// daemon on the D-Bus session bus.
+	Desktop bool `json:"desktop,omitempty"`
 }
 
-// SaveSettings writes the settings to the config file.
+// CalendarSource configures one calendar provider.
+type CalendarSource struct {
+	Type     string   `json:"type"` // "google", "caldav" or "ics"
+	Name     string   `json:"name,omitempty"`
+	URL      string   `json:"url,omitempty"` // CalDAV calendar collection URL
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	Paths    []string `json:"paths,omitempty"` // .ics files or vdir folders
+	Color    string   `json:"color,omitempty"` // hex color such as "#98c379"
+	// Account labels a Google account; each account has its own token file.
+	Account string `json:"account,omitempty"`
+	// Calendars lists the Google calendars to show by name or ID; the
+	// primary calendar is shown when empty.
+	Calendars []string          `json:"calendars,omitempty"`
+	Colors    map[string]string `json:"colors,omitempty"` // color per Google calendar name
+}
+
+// SaveSettings writes the settings to the config file. The file may hold
+// credentials, so only the user can read it.
 func SaveSettings(settings Settings) error {
 	configDir, err := GetConfigDir()
 	if err != nil {
@@ -124,7 +178,11 @@ func SaveSettings(settings Settings) error {
 		return err
 	}
 
-	return os.WriteFile(settingsPath, data, 0644)
+	if err := os.WriteFile(settingsPath, data, 0600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of existing files, written before 0600 was used.
+	return os.Chmod(settingsPath, 0600)
 }
 
 // LoadSettings reads settings from the config file, or creates a default one.
@@ -146,7 +204,7 @@ func LoadSettings() (Settings, error) {
 			if marshalErr != nil {
 				return settings, marshalErr
 			}
-			writeErr := os.WriteFile(settingsPath, data, 0644)
+			writeErr := os.WriteFile(settingsPath, data, 0600)
 			if writeErr != nil {
 				return settings, writeErr
 			}
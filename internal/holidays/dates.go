@@ -0,0 +1,81 @@
+package holidays
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// date is a yearly date from the dates file. Year is 0 when unknown.
+type date struct {
+	Year  int
+	Month time.Month
+	Day   int
+	Name  string
+}
+
+// readDates reads a dates file: one date per line, as "1990-04-12 Name" or,
+// without the year, "04-12 Name". Blank lines and lines starting with "#"
+// are skipped. A missing file lists no dates.
+func readDates(path string) ([]date, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = home + "/" + rest
+		}
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open dates file: %v", err)
+	}
+	defer file.Close()
+
+	var dates []date
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		when, name, _ := strings.Cut(line, " ")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("%s:%d: a date needs a name", path, n)
+		}
+		d := date{Name: name}
+		if t, err := time.Parse("2006-01-02", when); err == nil {
+			d.Year, d.Month, d.Day = t.Year(), t.Month(), t.Day()
+		} else if t, err := time.Parse("01-02", when); err == nil {
+			d.Month, d.Day = t.Month(), t.Day()
+		} else {
+			return nil, fmt.Errorf("%s:%d: %q is not a date like 1990-04-12 or 04-12", path, n, when)
+		}
+		dates = append(dates, d)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read dates file: %v", err)
+	}
+	return dates, nil
+}
+
+// on returns the date in year y, counting the years since it when the year
+// is known. February 29 falls on the 28th in common years.
+func (d date) on(y int) Holiday {
+	day := d.Day
+	if d.Month == time.February && day == 29 && !isLeap(y) {
+		day = 28
+	}
+	h := Holiday{Date: time.Date(y, d.Month, day, 0, 0, 0, 0, time.Local), Name: d.Name, Kind: Personal}
+	if d.Year != 0 && y > d.Year {
+		h.Name = fmt.Sprintf("%s (%d)", d.Name, y-d.Year)
+	}
+	return h
+}
+
+func isLeap(y int) bool {
+	return y%4 == 0 && (y%100 != 0 || y%400 == 0)
+}
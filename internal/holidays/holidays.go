@@ -0,0 +1,208 @@
+// Package holidays computes the public holidays of a country from bundled
+// rules, and reads the birthdays and anniversaries listed in a dates file.
+package holidays
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed rules.json
+var rulesJSON []byte
+
+// Kind tells public holidays from personal dates.
+type Kind int
+
+const (
+	Public   Kind = iota // public holiday of the configured country
+	Personal             // birthday or anniversary from the dates file
+)
+
+// Holiday is a holiday or personal date falling on Date, a local midnight.
+type Holiday struct {
+	Date time.Time
+	Name string
+	Kind Kind
+}
+
+// country is the rule set of a country in rules.json.
+type country struct {
+	Name     string `json:"name"`
+	Easter   string `json:"easter"` // "western" or "orthodox"
+	Holidays []rule `json:"holidays"`
+}
+
+// rule places a holiday in a year, either on a fixed date, a number of days
+// after Easter, or on the nth weekday of a month.
+type rule struct {
+	Name    string `json:"name"`
+	Date    string `json:"date,omitempty"`   // "MM-DD"
+	Easter  *int   `json:"easter,omitempty"` // days after Easter Sunday
+	Month   int    `json:"month,omitempty"`
+	Weekday string `json:"weekday,omitempty"`
+	Week    int    `json:"week,omitempty"`  // 1 for the first, -1 for the last
+	Since   int    `json:"since,omitempty"` // first year it is observed
+}
+
+func loadRules() (map[string]country, error) {
+	var countries map[string]country
+	if err := json.Unmarshal(rulesJSON, &countries); err != nil {
+		return nil, fmt.Errorf("could not parse holiday rules: %v", err)
+	}
+	return countries, nil
+}
+
+// Calendar answers which holidays and personal dates fall on a day.
+type Calendar struct {
+	country *country
+	dates   []date
+	years   map[int]map[string][]Holiday // by year, then by "2006-01-02"
+}
+
+// New returns the calendar of the country with the ISO 3166 code, which may
+// be empty for none, and the dates of datesFile, which may be empty or
+// missing.
+func New(code, datesFile string) (*Calendar, error) {
+	c := &Calendar{years: make(map[int]map[string][]Holiday)}
+	if code != "" {
+		countries, err := loadRules()
+		if err != nil {
+			return nil, err
+		}
+		rules, ok := countries[strings.ToUpper(code)]
+		if !ok {
+			var codes []string
+			for known := range countries {
+				codes = append(codes, known)
+			}
+			sort.Strings(codes)
+			return nil, fmt.Errorf("no holidays are known for country %q, only for %s", code, strings.Join(codes, ", "))
+		}
+		c.country = &rules
+	}
+	if datesFile != "" {
+		dates, err := readDates(datesFile)
+		if err != nil {
+			return nil, err
+		}
+		c.dates = dates
+	}
+	return c, nil
+}
+
+// On returns the public holidays and then the personal dates on day.
+func (c *Calendar) On(day time.Time) []Holiday {
+	if c == nil {
+		return nil
+	}
+	year, ok := c.years[day.Year()]
+	if !ok {
+		year = c.year(day.Year())
+		c.years[day.Year()] = year
+	}
+	return year[day.Format("2006-01-02")]
+}
+
+// year computes the holidays and personal dates of a year.
+func (c *Calendar) year(y int) map[string][]Holiday {
+	days := make(map[string][]Holiday)
+	add := func(h Holiday) {
+		key := h.Date.Format("2006-01-02")
+		days[key] = append(days[key], h)
+	}
+	if c.country != nil {
+		easter := WesternEaster(y)
+		if c.country.Easter == "orthodox" {
+			easter = OrthodoxEaster(y)
+		}
+		for _, r := range c.country.Holidays {
+			if r.Since != 0 && y < r.Since {
+				continue
+			}
+			if day, ok := r.on(y, easter); ok {
+				add(Holiday{Date: day, Name: r.Name, Kind: Public})
+			}
+		}
+	}
+	for _, d := range c.dates {
+		if d.Year <= y {
+			add(d.on(y))
+		}
+	}
+	return days
+}
+
+// on returns the day of the rule in year y.
+func (r rule) on(y int, easter time.Time) (time.Time, bool) {
+	switch {
+	case r.Date != "":
+		t, err := time.Parse("01-02", r.Date)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Date(y, t.Month(), t.Day(), 0, 0, 0, 0, time.Local), true
+	case r.Easter != nil:
+		return easter.AddDate(0, 0, *r.Easter), true
+	case r.Month != 0 && r.Week != 0:
+		weekday, ok := weekdays[strings.ToLower(r.Weekday)]
+		if !ok {
+			return time.Time{}, false
+		}
+		return nthWeekday(y, time.Month(r.Month), weekday, r.Week), true
+	}
+	return time.Time{}, false
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday,
+	"wednesday": time.Wednesday, "thursday": time.Thursday, "friday": time.Friday,
+	"saturday": time.Saturday,
+}
+
+// nthWeekday returns the nth weekday of a month, counting from the end of
+// the month when n is negative.
+func nthWeekday(y int, month time.Month, weekday time.Weekday, n int) time.Time {
+	if n < 0 {
+		last := time.Date(y, month+1, 0, 0, 0, 0, 0, time.Local)
+		back := (int(last.Weekday()) - int(weekday) + 7) % 7
+		return last.AddDate(0, 0, -back+7*(n+1))
+	}
+	first := time.Date(y, month, 1, 0, 0, 0, 0, time.Local)
+	ahead := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, ahead+7*(n-1))
+}
+
+// WesternEaster returns Easter Sunday of the Gregorian calendar, using the
+// anonymous Gregorian algorithm.
+func WesternEaster(y int) time.Time {
+	a := y % 19
+	b, c := y/100, y%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(y, time.Month(month), day, 0, 0, 0, 0, time.Local)
+}
+
+// OrthodoxEaster returns the Easter Sunday of the Orthodox churches, which
+// reckon it in the Julian calendar, as a Gregorian date.
+func OrthodoxEaster(y int) time.Time {
+	a, b, c := y%4, y%7, y%19
+	d := (19*c + 15) % 30
+	e := (2*a + 4*b - d + 34) % 7
+	month := (d + e + 114) / 31
+	day := (d+e+114)%31 + 1
+	// The Julian calendar lags 13 days from 1900 to 2099, and a day more
+	// after each century year not divisible by 400.
+	julianLag := y/100 - y/400 - 2
+	return time.Date(y, time.Month(month), day+julianLag, 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,91 @@
+package holidays
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEaster(t *testing.T) {
+	tests := []struct {
+		year     int
+		western  string
+		orthodox string
+	}{
+		{1961, "1961-04-02", "1961-04-09"},
+		{2000, "2000-04-23", "2000-04-30"},
+		{2008, "2008-03-23", "2008-04-27"},
+		{2010, "2010-04-04", "2010-04-04"},
+		{2019, "2019-04-21", "2019-04-28"},
+		{2024, "2024-03-31", "2024-05-05"},
+		{2025, "2025-04-20", "2025-04-20"},
+		{2026, "2026-04-05", "2026-04-12"},
+		{2027, "2027-03-28", "2027-05-02"},
+		{2038, "2038-04-25", "2038-04-25"},
+	}
+	for _, tt := range tests {
+		if got := WesternEaster(tt.year).Format("2006-01-02"); got != tt.western {
+			t.Errorf("WesternEaster(%d) = %s, want %s", tt.year, got, tt.western)
+		}
+		if got := OrthodoxEaster(tt.year).Format("2006-01-02"); got != tt.orthodox {
+			t.Errorf("OrthodoxEaster(%d) = %s, want %s", tt.year, got, tt.orthodox)
+		}
+		if got := WesternEaster(tt.year).Weekday(); got != time.Sunday {
+			t.Errorf("WesternEaster(%d) falls on a %s", tt.year, got)
+		}
+		if got := OrthodoxEaster(tt.year).Weekday(); got != time.Sunday {
+			t.Errorf("OrthodoxEaster(%d) falls on a %s", tt.year, got)
+		}
+	}
+}
+
+func TestOn(t *testing.T) {
+	datesFile := filepath.Join(t.TempDir(), "dates.txt")
+	dates := "# Birthdays\n1990-04-13 Maria's birthday\n\n02-29 Leap day\n"
+	if err := os.WriteFile(datesFile, []byte(dates), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		country string
+		date    string
+		want    []string
+	}{
+		{"GR", "2026-04-13", []string{"Easter Monday", "Maria's birthday (36)"}},
+		{"", "1990-04-13", []string{"Maria's birthday"}},
+		{"", "2026-02-28", []string{"Leap day"}},
+		{"", "2028-02-28", nil},
+		{"", "2028-02-29", []string{"Leap day"}},
+		{"DE", "2026-04-06", []string{"Easter Monday"}},
+		{"US", "2026-11-26", []string{"Thanksgiving Day"}},
+		{"US", "2026-05-25", []string{"Memorial Day"}},
+		{"GB", "2026-05-04", []string{"Early May Bank Holiday"}},
+		{"GR", "2026-04-06", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.country+tt.date, func(t *testing.T) {
+			c, err := New(tt.country, datesFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			day, err := time.ParseInLocation("2006-01-02", tt.date, time.Local)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, h := range c.On(day) {
+				got = append(got, h.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("On(%s) = %v, want %v", tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewUnknownCountry(t *testing.T) {
+	if _, err := New("XX", ""); err == nil {
+		t.Error("New(\"XX\") succeeded, want an error")
+	}
+}
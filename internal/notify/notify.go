@@ -0,0 +1,119 @@
+// Package notify sends desktop notifications through the freedesktop
+// Notifications D-Bus interface.
+package notify
+
+import (
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+
+	"GoDash/internal/sessionbus"
+)
+
+const (
+	notificationsName      = "org.freedesktop.Notifications"
+	notificationsPath      = dbus.ObjectPath("/org/freedesktop/Notifications")
+	notificationsInterface = "org.freedesktop.Notifications"
+)
+
+// Action is a notification button. Key is reported when it is clicked.
+type Action struct {
+	Key   string
+	Label string
+}
+
+// Invoked reports that the user clicked an action of a notification.
+type Invoked struct {
+	ID  uint32
+	Key string
+}
+
+// Notifier shows notifications on the desktop.
+type Notifier struct {
+	conn    *dbus.Conn
+	invoked chan Invoked
+}
+
+// Open connects to the notification daemon of the desktop session.
+func Open() (*Notifier, error) {
+	conn, err := sessionbus.Connect()
+	if err != nil {
+		return nil, err
+	}
+	var owned bool
+	err = conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, notificationsName).Store(&owned)
+	if err != nil || !owned {
+		var activatable []string
+		conn.BusObject().Call("org.freedesktop.DBus.ListActivatableNames", 0).Store(&activatable)
+		if !contains(activatable, notificationsName) {
+			return nil, fmt.Errorf("no notification daemon is running")
+		}
+	}
+
+	err = conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(notificationsPath),
+		dbus.WithMatchInterface(notificationsInterface),
+		dbus.WithMatchMember("ActionInvoked"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+
+	n := &Notifier{conn: conn, invoked: make(chan Invoked, 16)}
+	go n.forward(signals)
+	return n, nil
+}
+
+// forward passes on the clicks on actions.
+func (n *Notifier) forward(signals <-chan *dbus.Signal) {
+	for signal := range signals {
+		if signal.Name != notificationsInterface+".ActionInvoked" || len(signal.Body) != 2 {
+			continue
+		}
+		id, _ := signal.Body[0].(uint32)
+		key, _ := signal.Body[1].(string)
+		select {
+		case n.invoked <- Invoked{ID: id, Key: key}:
+		default: // nobody is listening
+		}
+	}
+}
+
+// Invoked returns the clicks on the actions of notifications, including
+// those of other applications, which have unknown IDs.
+func (n *Notifier) Invoked() <-chan Invoked {
+	return n.invoked
+}
+
+// Send shows a notification and returns its ID. A non-zero replaces
+// updates that notification instead of showing a new one.
+func (n *Notifier) Send(summary, body string, actions []Action, replaces uint32) (uint32, error) {
+	var list []string
+	for _, a := range actions {
+		list = append(list, a.Key, a.Label)
+	}
+	hints := map[string]dbus.Variant{"urgency": dbus.MakeVariant(byte(1))}
+	var id uint32
+	call := n.conn.Object(notificationsName, notificationsPath).Call(notificationsInterface+".Notify", 0,
+		"GoDash", replaces, "appointment-soon", summary, body, list, hints, int32(0))
+	if err := call.Store(&id); err != nil {
+		return 0, fmt.Errorf("could not send notification: %v", err)
+	}
+	return id, nil
+}
+
+// Close removes a notification from the desktop.
+func (n *Notifier) Close(id uint32) error {
+	return n.conn.Object(notificationsName, notificationsPath).Call(notificationsInterface+".CloseNotification", 0, id).Err
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"GoDash/internal/config"
+)
+
+// fileHeader starts the encrypted secrets file.
+const fileHeader = "GODASH-SECRETS v1\n"
+
+const fileKeySize = 32
+
+// FileStore keeps secrets in secrets.enc in the config directory, encrypted
+// with AES-256-GCM under a random key stored in secrets.key in the data
+// directory. Keeping the two apart means a copied or synced config directory
+// doesn't give the tokens away; it doesn't protect them from someone who can
+// read all of the user's files, which only a keyring can.
+type FileStore struct {
+	mu sync.Mutex
+}
+
+// NewFileStore returns the encrypted file store.
+func NewFileStore() *FileStore {
+	return &FileStore{}
+}
+
+func (s *FileStore) Name() string {
+	return "encrypted file"
+}
+
+func (s *FileStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	value, ok := secrets[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *FileStore) Set(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[key] = value
+	return s.save(secrets)
+}
+
+func (s *FileStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[key]; !ok {
+		return nil
+	}
+	delete(secrets, key)
+	return s.save(secrets)
+}
+
+// load decrypts the secrets file. A missing file holds no secrets.
+func (s *FileStore) load() (map[string][]byte, error) {
+	secrets := make(map[string][]byte)
+	path, err := secretsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read secrets: %v", err)
+	}
+
+	gcm, err := fileCipher(false)
+	if err != nil {
+		return nil, err
+	}
+	header := len(fileHeader)
+	if len(data) < header+gcm.NonceSize() || string(data[:header]) != fileHeader {
+		return nil, fmt.Errorf("%s is not a GoDash secrets file", path)
+	}
+	nonce := data[header : header+gcm.NonceSize()]
+	plaintext, err := gcm.Open(nil, nonce, data[header+gcm.NonceSize():], []byte(fileHeader))
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt %s, was secrets.key replaced?", path)
+	}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("could not parse secrets: %v", err)
+	}
+	return secrets, nil
+}
+
+// save encrypts secrets with a new nonce and replaces the secrets file.
+func (s *FileStore) save(secrets map[string][]byte) error {
+	path, err := secretsPath()
+	if err != nil {
+		return err
+	}
+	if len(secrets) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("could not remove secrets: %v", err)
+		}
+		return nil
+	}
+
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("could not marshal secrets: %v", err)
+	}
+	gcm, err := fileCipher(true)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data := append([]byte(fileHeader), nonce...)
+	data = gcm.Seal(data, nonce, plaintext, []byte(fileHeader))
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("could not write secrets: %v", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// fileCipher returns the cipher of the secrets file, creating its key if
+// create is set and there is none yet.
+func fileCipher(create bool) (cipher.AEAD, error) {
+	path, err := keyPath()
+	if err != nil {
+		return nil, err
+	}
+	key, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && create {
+		key = make([]byte, fileKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, err
+		}
+		err = os.WriteFile(path, key, 0600)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read the secrets key: %v", err)
+	}
+	if len(key) != fileKeySize {
+		return nil, fmt.Errorf("%s is not a GoDash secrets key", path)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func secretsPath() (string, error) {
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "secrets.enc"), nil
+}
+
+func keyPath() (string, error) {
+	dataDir, err := config.GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "secrets.key"), nil
+}
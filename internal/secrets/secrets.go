@@ -0,0 +1,120 @@
+// Package secrets stores credentials such as OAuth tokens. They are kept in
+// the system keyring through the Secret Service D-Bus API when one is
+// running, and in an encrypted file otherwise.
+package secrets
+
+import (
+	"errors"
+	"os"
+	"sync"
+)
+
+// ErrNotFound is returned when no secret is stored under a key.
+var ErrNotFound = errors.New("secret not found")
+
+// storeEnv names a variable choosing the store: "keyring" or "file".
+const storeEnv = "GODASH_SECRET_STORE"
+
+// Store keeps secrets by key.
+type Store interface {
+	// Name describes where the secrets are kept.
+	Name() string
+	// Get returns the secret stored under key, or ErrNotFound.
+	Get(key string) ([]byte, error)
+	// Set stores value under key, replacing any previous secret.
+	Set(key string, value []byte) error
+	// Delete removes the secret stored under key, if any.
+	Delete(key string) error
+}
+
+var (
+	defaultOnce  sync.Once
+	defaultStore Store
+)
+
+// Default returns the store used for GoDash's credentials: the Secret
+// Service keyring when one is running, otherwise the encrypted file. Setting
+// GODASH_SECRET_STORE to "file" or "keyring" picks one explicitly. Secrets
+// are looked up in the keyring first and then in the file, so those saved
+// by a session that used the other store are still found.
+func Default() Store {
+	defaultOnce.Do(func() {
+		defaultStore = openDefault(os.Getenv(storeEnv))
+	})
+	return defaultStore
+}
+
+func openDefault(choice string) Store {
+	file := NewFileStore()
+	keyring, err := OpenSecretService()
+	switch {
+	case err != nil && choice == "keyring":
+		return failingStore{err: err}
+	case err != nil:
+		return file
+	case choice == "file":
+		return &layeredStore{stores: []Store{file, keyring}, lookup: []Store{keyring, file}}
+	}
+	return &layeredStore{stores: []Store{keyring, file}, lookup: []Store{keyring, file}}
+}
+
+// layeredStore saves secrets in its first store and looks them up in the
+// lookup order.
+type layeredStore struct {
+	stores []Store // the first one is written to
+	lookup []Store
+}
+
+func (s *layeredStore) Name() string {
+	return s.stores[0].Name()
+}
+
+func (s *layeredStore) Get(key string) ([]byte, error) {
+	var firstErr error
+	for _, store := range s.lookup {
+		value, err := store.Get(key)
+		if err == nil {
+			return value, nil
+		}
+		if !errors.Is(err, ErrNotFound) && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return nil, ErrNotFound
+}
+
+// Set stores value in the first store and removes older copies from the
+// others, which could otherwise be found first.
+func (s *layeredStore) Set(key string, value []byte) error {
+	if err := s.stores[0].Set(key, value); err != nil {
+		return err
+	}
+	for _, store := range s.stores[1:] {
+		store.Delete(key)
+	}
+	return nil
+}
+
+func (s *layeredStore) Delete(key string) error {
+	var firstErr error
+	for _, store := range s.stores {
+		if err := store.Delete(key); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// failingStore is used when the keyring was asked for but isn't available,
+// so credentials don't silently end up somewhere else.
+type failingStore struct {
+	err error
+}
+
+func (s failingStore) Name() string                       { return "keyring (unavailable)" }
+func (s failingStore) Get(key string) ([]byte, error)     { return nil, s.err }
+func (s failingStore) Set(key string, value []byte) error { return s.err }
+func (s failingStore) Delete(key string) error            { return s.err }
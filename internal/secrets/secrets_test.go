@@ -0,0 +1,89 @@
+package secrets
+
+import (
+	"errors"
+	"testing"
+)
+
+// memStore keeps secrets in memory.
+type memStore struct {
+	name    string
+	secrets map[string]string
+	err     error // returned by Get, if set
+}
+
+func (s *memStore) Name() string { return s.name }
+
+func (s *memStore) Get(key string) ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	value, ok := s.secrets[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return []byte(value), nil
+}
+
+func (s *memStore) Set(key string, value []byte) error {
+	s.secrets[key] = string(value)
+	return nil
+}
+
+func (s *memStore) Delete(key string) error {
+	delete(s.secrets, key)
+	return nil
+}
+
+func TestLayeredStoreGet(t *testing.T) {
+	errLocked := errors.New("keyring is locked")
+	tests := []struct {
+		name    string
+		keyring map[string]string
+		file    map[string]string
+		keyErr  error
+		want    string
+		wantErr error
+	}{
+		{name: "in the keyring", keyring: map[string]string{"token": "k"}, file: map[string]string{"token": "f"}, want: "k"},
+		{name: "only in the file", keyring: map[string]string{}, file: map[string]string{"token": "f"}, want: "f"},
+		{name: "nowhere", keyring: map[string]string{}, file: map[string]string{}, wantErr: ErrNotFound},
+		{name: "locked keyring falls back", keyring: map[string]string{}, file: map[string]string{"token": "f"}, keyErr: errLocked, want: "f"},
+		{name: "locked keyring is reported", keyring: map[string]string{}, file: map[string]string{}, keyErr: errLocked, wantErr: errLocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring := &memStore{name: "keyring", secrets: tt.keyring, err: tt.keyErr}
+			file := &memStore{name: "file", secrets: tt.file}
+			// Written to the file, as with GODASH_SECRET_STORE=file.
+			s := &layeredStore{stores: []Store{file, keyring}, lookup: []Store{keyring, file}}
+			got, err := s.Get("token")
+			if !errors.Is(err, tt.wantErr) || string(got) != tt.want {
+				t.Errorf("Get() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLayeredStoreSetReplacesOlderCopies(t *testing.T) {
+	keyring := &memStore{name: "keyring", secrets: map[string]string{}}
+	file := &memStore{name: "file", secrets: map[string]string{"token": "old"}}
+	s := &layeredStore{stores: []Store{keyring, file}, lookup: []Store{keyring, file}}
+
+	if err := s.Set("token", []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if keyring.secrets["token"] != "new" {
+		t.Errorf("keyring holds %q, want %q", keyring.secrets["token"], "new")
+	}
+	if _, ok := file.secrets["token"]; ok {
+		t.Error("the file still holds the old secret")
+	}
+
+	if err := s.Delete("token"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("token"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want %v", err, ErrNotFound)
+	}
+}
@@ -0,0 +1,191 @@
+package secrets
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"GoDash/internal/sessionbus"
+)
+
+const (
+	secretServiceName = "org.freedesktop.secrets"
+	secretServicePath = dbus.ObjectPath("/org/freedesktop/secrets")
+
+	serviceInterface    = "org.freedesktop.Secret.Service"
+	collectionInterface = "org.freedesktop.Secret.Collection"
+	itemInterface       = "org.freedesktop.Secret.Item"
+	promptInterface     = "org.freedesktop.Secret.Prompt"
+
+	// promptTimeout is how long the user has to unlock the keyring.
+	promptTimeout = 2 * time.Minute
+)
+
+// secret is the Secret struct of the Secret Service API.
+type secret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+// SecretService keeps secrets in the default collection of the Secret
+// Service, such as GNOME Keyring or KWallet. Items are found by their
+// "application" and "key" attributes.
+type SecretService struct {
+	conn       *dbus.Conn
+	session    dbus.ObjectPath
+	collection dbus.ObjectPath
+}
+
+// OpenSecretService connects to the Secret Service on the session bus. It
+// fails when no session bus or no keyring is running, e.g. over SSH.
+func OpenSecretService() (*SecretService, error) {
+	conn, err := sessionbus.Connect()
+	if err != nil {
+		return nil, err
+	}
+	service := conn.Object(secretServiceName, secretServicePath)
+
+	var output dbus.Variant
+	var session dbus.ObjectPath
+	// Secrets travel unencrypted over the private session bus, as with most
+	// Secret Service clients.
+	err = service.Call(serviceInterface+".OpenSession", 0, "plain", dbus.MakeVariant("")).Store(&output, &session)
+	if err != nil {
+		return nil, fmt.Errorf("no Secret Service: %v", err)
+	}
+
+	var collection dbus.ObjectPath
+	err = service.Call(serviceInterface+".ReadAlias", 0, "default").Store(&collection)
+	if err != nil {
+		return nil, fmt.Errorf("could not find the default keyring: %v", err)
+	}
+	if collection == "/" {
+		return nil, fmt.Errorf("no default keyring")
+	}
+	return &SecretService{conn: conn, session: session, collection: collection}, nil
+}
+
+func (s *SecretService) Name() string {
+	return "system keyring"
+}
+
+func (s *SecretService) Get(key string) ([]byte, error) {
+	item, err := s.find(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.unlock(item); err != nil {
+		return nil, err
+	}
+	var sec secret
+	err = s.conn.Object(secretServiceName, item).Call(itemInterface+".GetSecret", 0, s.session).Store(&sec)
+	if err != nil {
+		return nil, fmt.Errorf("could not read secret: %v", err)
+	}
+	return sec.Value, nil
+}
+
+func (s *SecretService) Set(key string, value []byte) error {
+	if err := s.unlock(s.collection); err != nil {
+		return err
+	}
+	properties := map[string]dbus.Variant{
+		itemInterface + ".Label":      dbus.MakeVariant("GoDash " + key),
+		itemInterface + ".Attributes": dbus.MakeVariant(attributes(key)),
+	}
+	sec := secret{Session: s.session, Value: value, ContentType: "application/octet-stream"}
+
+	var item, prompt dbus.ObjectPath
+	call := s.conn.Object(secretServiceName, s.collection).Call(collectionInterface+".CreateItem", 0, properties, sec, true)
+	if err := call.Store(&item, &prompt); err != nil {
+		return fmt.Errorf("could not store secret: %v", err)
+	}
+	return s.prompt(prompt)
+}
+
+func (s *SecretService) Delete(key string) error {
+	item, err := s.find(key)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var prompt dbus.ObjectPath
+	if err := s.conn.Object(secretServiceName, item).Call(itemInterface+".Delete", 0).Store(&prompt); err != nil {
+		return fmt.Errorf("could not delete secret: %v", err)
+	}
+	return s.prompt(prompt)
+}
+
+// attributes identify the item of key.
+func attributes(key string) map[string]string {
+	return map[string]string{"application": "GoDash", "key": key}
+}
+
+// find returns the item stored under key.
+func (s *SecretService) find(key string) (dbus.ObjectPath, error) {
+	var items []dbus.ObjectPath
+	err := s.conn.Object(secretServiceName, s.collection).Call(collectionInterface+".SearchItems", 0, attributes(key)).Store(&items)
+	if err != nil {
+		return "", fmt.Errorf("could not search the keyring: %v", err)
+	}
+	if len(items) == 0 {
+		return "", ErrNotFound
+	}
+	return items[0], nil
+}
+
+// unlock unlocks an item or collection, asking the user for the keyring
+// password if needed.
+func (s *SecretService) unlock(object dbus.ObjectPath) error {
+	var unlocked []dbus.ObjectPath
+	var prompt dbus.ObjectPath
+	err := s.conn.Object(secretServiceName, secretServicePath).Call(serviceInterface+".Unlock", 0, []dbus.ObjectPath{object}).Store(&unlocked, &prompt)
+	if err != nil {
+		return fmt.Errorf("could not unlock the keyring: %v", err)
+	}
+	return s.prompt(prompt)
+}
+
+// prompt shows a Secret Service prompt and waits for the user to answer.
+// "/" means no prompt is needed.
+func (s *SecretService) prompt(prompt dbus.ObjectPath) error {
+	if prompt == "/" || prompt == "" {
+		return nil
+	}
+	match := []dbus.MatchOption{
+		dbus.WithMatchObjectPath(prompt),
+		dbus.WithMatchInterface(promptInterface),
+		dbus.WithMatchMember("Completed"),
+	}
+	if err := s.conn.AddMatchSignal(match...); err != nil {
+		return err
+	}
+	defer s.conn.RemoveMatchSignal(match...)
+	signals := make(chan *dbus.Signal, 1)
+	s.conn.Signal(signals)
+	defer s.conn.RemoveSignal(signals)
+
+	if err := s.conn.Object(secretServiceName, prompt).Call(promptInterface+".Prompt", 0, "").Err; err != nil {
+		return fmt.Errorf("could not ask to unlock the keyring: %v", err)
+	}
+	timeout := time.After(promptTimeout)
+	for {
+		select {
+		case signal := <-signals:
+			if signal.Path != prompt || signal.Name != promptInterface+".Completed" {
+				continue
+			}
+			if len(signal.Body) > 0 && signal.Body[0] == true {
+				return fmt.Errorf("the keyring was not unlocked")
+			}
+			return nil
+		case <-timeout:
+			return fmt.Errorf("the keyring was not unlocked in time")
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// Package sessionbus connects to the D-Bus session bus of the desktop
+// GoDash runs on, which offers the keyring and desktop notifications.
+package sessionbus
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// ErrNoSession is returned when there is no session bus, e.g. over SSH
+// without a user session.
+var ErrNoSession = errors.New("no D-Bus session bus")
+
+// Connect returns the shared connection to the session bus. Unlike
+// dbus.SessionBus it never launches a bus of its own when none is running.
+func Connect() (*dbus.Conn, error) {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+		if runtimeDir == "" {
+			return nil, ErrNoSession
+		}
+		if _, err := os.Stat(filepath.Join(runtimeDir, "bus")); err != nil {
+			return nil, ErrNoSession
+		}
+	}
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoSession, err)
+	}
+	return conn, nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -16,6 +18,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 
 	"GoDash/internal/config"
 	calendarwidget "GoDash/widgets/calendar"
@@ -86,6 +89,9 @@ const (
 	stateSetupWeather
 	stateSetupCalendar
 	stateExitConfirmation
+	statePassphrasePrompt
+	stateDraftRecovery
+	stateEventDetail
 )
 
 // Note Editor Modes
@@ -94,6 +100,7 @@ type noteEditorMode int
 const (
 	notePreviewMode noteEditorMode = iota
 	noteSourceMode
+	noteSplitMode // source and live preview side by side
 )
 
 const (
@@ -125,6 +132,7 @@ var (
 	blueText          = lipgloss.NewStyle().Foreground(lipgloss.Color("81"))
 	orangeText        = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
 	redText           = lipgloss.NewStyle().Foreground(lipgloss.Color("#e06c75"))
+	reminderStyle     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#e5c07b"))
 )
 
 // --- KEYS ---
@@ -136,13 +144,43 @@ type keyMap struct {
 	SaveTask        key.Binding
 	Confirm         key.Binding
 	OpenLink        key.Binding
+	DeviceCode      key.Binding
+	ShowQRCode      key.Binding
 	OpenCalendar    key.Binding
+	ShowEvent       key.Binding
+	AddEvent        key.Binding
+	EditEvent       key.Binding
+	DeleteEvent     key.Binding
+	NextEvent       key.Binding
+	PrevEvent       key.Binding
+	SwitchView      key.Binding
+	Refresh         key.Binding
+	SnoozeReminder  key.Binding
+	DismissReminder key.Binding
 	Cancel          key.Binding
 	CreateNote      key.Binding
 	DeleteNote      key.Binding
 	EditNote        key.Binding
 	SaveNote        key.Binding
 	ToggleEditMode  key.Binding
+	ShowChecklist   key.Binding
+	ToggleCheckbox  key.Binding
+	PromoteTask     key.Binding
+	EncryptNote     key.Binding
+	NextCheckbox    key.Binding
+	PrevCheckbox    key.Binding
+	NextImage       key.Binding
+	PrevImage       key.Binding
+	OpenImage       key.Binding
+	AttachFile      key.Binding
+	ShowOutline     key.Binding
+	ToggleSplit     key.Binding
+	Find            key.Binding
+	Replace         key.Binding
+	GoToLine        key.Binding
+	Undo            key.Binding
+	Redo            key.Binding
+	LineNumbers     key.Binding
 	CycleFocus      key.Binding
 	ShowHelp        key.Binding
 	Quit            key.Binding
@@ -157,13 +195,43 @@ var keys = keyMap{
 	SaveTask:       key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "save task")),
 	Confirm:        key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "confirm")),
 	OpenLink:       key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "open/authorize")),
-	OpenCalendar:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open calendar")),
+	DeviceCode:     key.NewBinding(key.WithKeys("ctrl+t"), key.WithHelp("ctrl+t", "sign in with a code")),
+	ShowQRCode:     key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "show QR code")),
+	OpenCalendar:   key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "open calendar")),
+	ShowEvent:      key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "event details")),
+	AddEvent:       key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "add event")),
+	EditEvent:      key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit event")),
+	DeleteEvent:    key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "delete event")),
+	NextEvent:      key.NewBinding(key.WithKeys("]"), key.WithHelp("]", "next event")),
+	PrevEvent:      key.NewBinding(key.WithKeys("["), key.WithHelp("[", "previous event")),
+	SwitchView:     key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "month/week/agenda")),
+	Refresh:        key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+	SnoozeReminder: key.NewBinding(key.WithKeys("z"), key.WithHelp("z", "snooze reminder")),
+	DismissReminder: key.NewBinding(key.WithKeys("Z"), key.WithHelp("Z", "dismiss reminder")),
 	Cancel:         key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
 	CreateNote:     key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "new note")),
 	DeleteNote:     key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "delete note")),
 	EditNote:       key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit note")),
 	SaveNote:       key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "save note")),
 	ToggleEditMode: key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "toggle edit mode")),
+	ShowChecklist:  key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "open checklist items")),
+	ToggleCheckbox: key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "toggle checkbox")),
+	PromoteTask:    key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "promote to todo")),
+	EncryptNote:    key.NewBinding(key.WithKeys("ctrl+e"), key.WithHelp("ctrl+e", "encrypt/decrypt note")),
+	NextCheckbox:   key.NewBinding(key.WithKeys("]"), key.WithHelp("]", "next checkbox")),
+	PrevCheckbox:   key.NewBinding(key.WithKeys("["), key.WithHelp("[", "previous checkbox")),
+	NextImage:      key.NewBinding(key.WithKeys("}"), key.WithHelp("}", "next image")),
+	PrevImage:      key.NewBinding(key.WithKeys("{"), key.WithHelp("{", "previous image")),
+	OpenImage:      key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "open image")),
+	AttachFile:     key.NewBinding(key.WithKeys("alt+a"), key.WithHelp("alt+a", "attach file")),
+	ShowOutline:    key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "outline")),
+	ToggleSplit:    key.NewBinding(key.WithKeys("ctrl+x"), key.WithHelp("ctrl+x", "split preview")),
+	Find:           key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "find")),
+	Replace:        key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "replace")),
+	GoToLine:       key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("ctrl+g", "go to line")),
+	Undo:           key.NewBinding(key.WithKeys("ctrl+z"), key.WithHelp("ctrl+z", "undo")),
+	Redo:           key.NewBinding(key.WithKeys("ctrl+y"), key.WithHelp("ctrl+y", "redo")),
+	LineNumbers:    key.NewBinding(key.WithKeys("ctrl+l"), key.WithHelp("ctrl+l", "toggle line numbers")),
 	CycleFocus:     key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "cycle focus")),
 	ShowHelp:       key.NewBinding(key.WithKeys("ctrl+k"), key.WithHelp("ctrl+k", "key bindings")),
 	Quit:           key.NewBinding(key.WithKeys("ctrl+q"), key.WithHelp("ctrl+q", "quit")),
@@ -177,7 +245,9 @@ func (m model) FullHelp() [][]key.Binding {
 	switch m.focus {
 	case focusCalendar:
 		return [][]key.Binding{
-			{m.keys.OpenCalendar},
+			{m.keys.ShowEvent, m.keys.OpenCalendar, m.keys.AddEvent, m.keys.EditEvent, m.keys.DeleteEvent},
+			{m.keys.PrevEvent, m.keys.NextEvent, m.keys.SwitchView, m.keys.Refresh},
+			{m.keys.SnoozeReminder, m.keys.DismissReminder},
 			{m.keys.CycleFocus, m.keys.ShowHelp, m.keys.Quit},
 		}
 	case focusNotes:
@@ -189,9 +259,14 @@ func (m model) FullHelp() [][]key.Binding {
 				{m.keys.Confirm, m.keys.Cancel},
 				{m.keys.CycleFocus, m.keys.ShowHelp, m.keys.Quit},
 			}
+		case notes.NoteStateChecklist:
+			return [][]key.Binding{
+				{m.keys.ToggleCheckbox, m.keys.PromoteTask, m.keys.Cancel},
+				{m.keys.CycleFocus, m.keys.ShowHelp, m.keys.Quit},
+			}
 		default: // NoteStateList
 			return [][]key.Binding{
-				{m.keys.CreateNote, m.keys.DeleteNote, m.keys.EditNote, m.keys.Confirm},
+				{m.keys.CreateNote, m.keys.DeleteNote, m.keys.EditNote, m.keys.Confirm, m.keys.ShowChecklist, m.keys.EncryptNote},
 				{m.keys.SaveNote, m.keys.ToggleEditMode, exitEditorKey},
 				{m.keys.CycleFocus, m.keys.ShowHelp, m.keys.Quit},
 			}
@@ -221,6 +296,17 @@ type model struct {
 	noteViewer       viewport.Model
 	noteEditorMode   noteEditorMode
 	noteContent      string
+	notePreviewLines []string
+	outline          []notes.Heading
+	outlineCursor    int
+	showOutline      bool
+	editHistory      editHistory
+	editorPrompt     editorPrompt
+	lastSearch       string
+	noteChecklist    []notes.ChecklistItem
+	checklistCursor  int
+	noteImages       []notes.ImageRef
+	imageCursor      int
 	editingNotePath  string
 	setupTextInput   textinput.Model
 	help             help.Model
@@ -230,18 +316,54 @@ type model struct {
 	spinner          spinner.Model
 	showHelp         bool
 	calendarAuthURL  string
+	calendarAccount  string // Google account being authorized
+	calendarAuthErr  error  // why the last authorization attempt failed
+	calendarDevice   *calendarwidget.DeviceCode // set while signing in with a code
+	googleDisabled   bool // the Google calendars were skipped for this session
+	showDeviceQR     bool
 	err              error
 	markdownRenderer *glamour.TermRenderer
+	markdownStyle    string // glamour standard style matching the terminal background
+	splitRenderer    *glamour.TermRenderer
+	splitRendererWidth  int
+	splitPreviewVersion int
 	saveMessage      string
 	saveMessageTimer int
 	hasUnsavedChanges bool
 	originalContent  string
 	confirmationChoice int // 0 = Yes, 1 = No
+	passphrase       passphrasePrompt
+	eventDetail      eventDetail
+	notePassphrase   string // key of the open encrypted note, kept only in memory
+	lastDraft        string
+	draftRecovery    draftRecovery
 }
 
 // tickMsg is sent periodically to update the save message timer
 type tickMsg time.Time
 
+// calendarAuthDoneMsg reports the end of the automatic calendar authorization
+type calendarAuthDoneMsg struct{ err error }
+
+// waitForCalendarAuth waits for the browser to finish the authorization
+func waitForCalendarAuth() tea.Msg {
+	return calendarAuthDoneMsg{err: calendarwidget.WaitForAuth()}
+}
+
+// calendarDeviceMsg carries the code of a started device authorization
+type calendarDeviceMsg struct {
+	code calendarwidget.DeviceCode
+	err  error
+}
+
+// startCalendarDeviceAuth asks Google for a code to sign in from another device
+func startCalendarDeviceAuth(account string) tea.Cmd {
+	return func() tea.Msg {
+		code, err := calendarwidget.StartDeviceAuth(account)
+		return calendarDeviceMsg{code: code, err: err}
+	}
+}
+
 // tickCmd sends a tick every second
 func tickCmd() tea.Cmd {
 	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
@@ -280,6 +402,12 @@ func initialModel(settings config.Settings) model {
 	if err != nil {
 		renderer = nil
 	}
+	// Remember the detected style for renderers created while running, when
+	// querying the terminal background would interfere with input.
+	markdownStyle := "dark"
+	if !lipgloss.HasDarkBackground() {
+		markdownStyle = "light"
+	}
 
 	todoKeys := todo.KeyMap{
 		AddTask:    keys.AddTask,
@@ -292,16 +420,29 @@ func initialModel(settings config.Settings) model {
 	}
 
 	noteKeys := notes.KeyMap{
-		CreateNote: keys.CreateNote,
-		DeleteNote: keys.DeleteNote,
-		EditNote:   keys.EditNote,
-		SaveNote:   keys.SaveNote,
-		Confirm:    keys.Confirm,
-		Cancel:     keys.Cancel,
+		CreateNote:    keys.CreateNote,
+		DeleteNote:    keys.DeleteNote,
+		EditNote:      keys.EditNote,
+		SaveNote:      keys.SaveNote,
+		Confirm:       keys.Confirm,
+		Cancel:        keys.Cancel,
+		ShowChecklist: keys.ShowChecklist,
+		ToggleItem:    keys.ToggleCheckbox,
+		PromoteItem:   keys.PromoteTask,
+
+		ToggleEncryption: keys.EncryptNote,
 	}
 
 	calendarKeys := calendarwidget.KeyMap{
-		Confirm: keys.Confirm,
+		Confirm:     keys.Confirm,
+		Cancel:      keys.Cancel,
+		AddEvent:    keys.AddEvent,
+		EditEvent:   keys.EditEvent,
+		DeleteEvent: keys.DeleteEvent,
+		NextEvent:   keys.NextEvent,
+		PrevEvent:   keys.PrevEvent,
+		SwitchView:  keys.SwitchView,
+		Refresh:     keys.Refresh,
 	}
 
 	todoPath, err := config.GetTodoPath()
@@ -317,28 +458,23 @@ func initialModel(settings config.Settings) model {
 		noteEditor:       noteTa,
 		noteViewer:       noteVp,
 		noteEditorMode:   notePreviewMode,
-		calendar:         calendarwidget.New(calendarKeys, settings.Location),
+		calendar:         calendarwidget.New(calendarKeys, settings.Location, settings.Calendars, settings.Reminders, settings.Holidays),
 		setupTextInput:   setupTI,
 		help:             h,
 		keys:             keys,
 		settings:         settings,
 		focus:            focusList,
 		markdownRenderer: renderer,
+		markdownStyle:    markdownStyle,
 	}
 
-	if !calendarwidget.IsAuthorized() {
-		m.state = stateSetupCalendar
-		authURL, err := calendarwidget.GetAuthURL()
-		if err != nil {
-			m.err = err
-		}
-		m.calendarAuthURL = authURL
-		if calendarwidget.IsUsingManualFlow() {
-			m.setupTextInput.Placeholder = "Paste authorization code here..."
-		} else {
-			m.setupTextInput.Placeholder = "Authorization will complete automatically..."
+	if len(calendarwidget.GoogleAccounts(settings.Calendars)) > 0 {
+		if err := calendarwidget.CheckCredentials(); err != nil {
+			m.disableGoogle(err)
 		}
-		m.setupTextInput.Focus()
+	}
+	if account, ok := m.unauthorizedAccount(); ok {
+		m.startCalendarAuth(account)
 	} else {
 		m.state = stateDashboard
 	}
@@ -348,7 +484,7 @@ func initialModel(settings config.Settings) model {
 }
 
 func (m model) Init() tea.Cmd {
-	cmds := []tea.Cmd{textinput.Blink, textarea.Blink}
+	cmds := []tea.Cmd{textinput.Blink, textarea.Blink, draftTickCmd()}
 	if m.state == stateDashboard {
 		cmds = append(cmds, m.calendar.Init())
 	}
@@ -366,15 +502,35 @@ func (m *model) updateKeybindings() {
 		m.keys.EditTask.SetEnabled(false)
 		m.keys.Confirm.SetEnabled(false)
 		m.keys.OpenLink.SetEnabled(false)
+		m.keys.DeviceCode.SetEnabled(false)
+		m.keys.ShowQRCode.SetEnabled(false)
 		m.keys.OpenCalendar.SetEnabled(false)
+		m.keys.ShowEvent.SetEnabled(false)
+		for _, binding := range []*key.Binding{&m.keys.AddEvent, &m.keys.EditEvent, &m.keys.DeleteEvent, &m.keys.NextEvent, &m.keys.PrevEvent, &m.keys.SwitchView, &m.keys.Refresh, &m.keys.SnoozeReminder, &m.keys.DismissReminder} {
+			binding.SetEnabled(false)
+		}
 		m.keys.CreateNote.SetEnabled(false)
 		m.keys.DeleteNote.SetEnabled(false)
 		m.keys.EditNote.SetEnabled(false)
 		m.keys.CycleFocus.SetEnabled(false)
 		m.keys.ShowHelp.SetEnabled(false)
 
-		m.keys.SaveNote.SetEnabled(m.noteEditorMode == noteSourceMode)
+		m.keys.SaveNote.SetEnabled(m.editingSource())
 		m.keys.ToggleEditMode.SetEnabled(true)
+		m.keys.ShowChecklist.SetEnabled(false)
+		m.keys.PromoteTask.SetEnabled(false)
+		m.keys.EncryptNote.SetEnabled(false)
+		m.keys.ToggleCheckbox.SetEnabled(m.noteEditorMode == notePreviewMode)
+		m.keys.NextCheckbox.SetEnabled(m.noteEditorMode == notePreviewMode)
+		m.keys.PrevCheckbox.SetEnabled(m.noteEditorMode == notePreviewMode)
+		m.keys.NextImage.SetEnabled(m.noteEditorMode == notePreviewMode)
+		m.keys.PrevImage.SetEnabled(m.noteEditorMode == notePreviewMode)
+		m.keys.OpenImage.SetEnabled(m.noteEditorMode == notePreviewMode)
+		m.keys.ShowOutline.SetEnabled(true)
+		m.keys.ToggleSplit.SetEnabled(true)
+		for _, binding := range []*key.Binding{&m.keys.Find, &m.keys.Replace, &m.keys.GoToLine, &m.keys.Undo, &m.keys.Redo, &m.keys.LineNumbers, &m.keys.AttachFile} {
+			binding.SetEnabled(m.editingSource())
+		}
 		m.keys.Cancel.SetEnabled(true) // For exiting the editor
 		m.keys.Quit.SetEnabled(true)
 		return
@@ -392,15 +548,43 @@ func (m *model) updateKeybindings() {
 	m.keys.Delete.SetEnabled(!isSetup && isListFocused && m.todo.GetState() == todo.ListStateDefault)
 	m.keys.Toggle.SetEnabled(!isSetup && isListFocused && m.todo.GetState() == todo.ListStateDefault)
 	m.keys.EditTask.SetEnabled(!isSetup && isListFocused && m.todo.GetState() == todo.ListStateDefault)
-	m.keys.Confirm.SetEnabled((!isSetup && isListFocused && (m.todo.GetState() == todo.ListStateAdding || m.todo.GetState() == todo.ListStateEditing)) || isSetup)
+	m.keys.Confirm.SetEnabled((!isSetup && isListFocused && (m.todo.GetState() == todo.ListStateAdding || m.todo.GetState() == todo.ListStateEditing)) || (!isSetup && isCalendarFocused && m.calendar.IsEditing()) || isSetup)
 	m.keys.OpenLink.SetEnabled(isSetup)
-	m.keys.OpenCalendar.SetEnabled(!isSetup && isCalendarFocused)
+	m.keys.DeviceCode.SetEnabled(isSetupCalendar)
+	m.keys.ShowQRCode.SetEnabled(isSetupCalendar)
+	m.keys.OpenCalendar.SetEnabled(!isSetup && isCalendarFocused && !m.calendar.IsEditing())
+	m.keys.ShowEvent.SetEnabled(!isSetup && isCalendarFocused && !m.calendar.IsEditing())
+	for _, binding := range []*key.Binding{&m.keys.AddEvent, &m.keys.EditEvent, &m.keys.DeleteEvent, &m.keys.NextEvent, &m.keys.PrevEvent, &m.keys.SwitchView, &m.keys.Refresh} {
+		binding.SetEnabled(!isSetup && isCalendarFocused && !m.calendar.IsEditing())
+	}
+	// The reminder keys work from any panel, but not while a task, an event
+	// or a list filter is being typed or a note checklist is open.
+	typing := m.todo.GetState() != todo.ListStateDefault || m.calendar.IsEditing() ||
+		m.todo.List.FilterState() == list.Filtering || m.notes.List.FilterState() == list.Filtering
+	reminderKeys := !isSetup && m.calendar.HasReminder() && !typing && m.notes.State == notes.NoteStateList
+	m.keys.SnoozeReminder.SetEnabled(reminderKeys)
+	m.keys.DismissReminder.SetEnabled(reminderKeys)
 	m.keys.CreateNote.SetEnabled(!isSetup && isNotesFocused)
 	m.keys.DeleteNote.SetEnabled(!isSetup && isNotesFocused)
 	m.keys.EditNote.SetEnabled(!isSetup && isNotesFocused)
+	m.keys.ShowChecklist.SetEnabled(!isSetup && isNotesFocused && m.notes.State == notes.NoteStateList)
+	m.keys.EncryptNote.SetEnabled(!isSetup && isNotesFocused && m.notes.State == notes.NoteStateList)
+	m.keys.ToggleCheckbox.SetEnabled(!isSetup && isNotesFocused && m.notes.State == notes.NoteStateChecklist)
+	m.keys.PromoteTask.SetEnabled(!isSetup && isNotesFocused && m.notes.State == notes.NoteStateChecklist)
+	m.keys.NextCheckbox.SetEnabled(false)
+	m.keys.PrevCheckbox.SetEnabled(false)
+	m.keys.NextImage.SetEnabled(false)
+	m.keys.PrevImage.SetEnabled(false)
+	m.keys.OpenImage.SetEnabled(false)
+	m.keys.AttachFile.SetEnabled(false)
+	m.keys.ShowOutline.SetEnabled(false)
+	m.keys.ToggleSplit.SetEnabled(false)
+	for _, binding := range []*key.Binding{&m.keys.Find, &m.keys.Replace, &m.keys.GoToLine, &m.keys.Undo, &m.keys.Redo, &m.keys.LineNumbers} {
+		binding.SetEnabled(false)
+	}
 	m.keys.CycleFocus.SetEnabled(!isSetup)
 	m.keys.SaveNote.SetEnabled(false)
-	m.keys.Cancel.SetEnabled(!isSetup)
+	m.keys.Cancel.SetEnabled(!isSetup || isSetupCalendar)
 	m.keys.ShowHelp.SetEnabled(true)
 	m.keys.Quit.SetEnabled(true)
 }
@@ -415,6 +599,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, tickCmd()
 		}
+	case draftTickMsg:
+		m.autosaveDraft()
+		return m, draftTickCmd()
+	case calendarwidget.AuthRequiredMsg:
+		// Screens with unsaved work are left alone; the next refresh asks again.
+		if m.state != stateDashboard && m.state != stateEventDetail {
+			return m, nil
+		}
+		if account, ok := m.unauthorizedAccount(); ok {
+			m.startCalendarAuth(account)
+			if m.calendarAuthErr == nil {
+				m.calendarAuthErr = msg.Err
+			}
+			m.updateKeybindings()
+			return m, textinput.Blink
+		}
+		return m, nil
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -422,42 +623,108 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.setupTextInput.Width = min(50, m.width-10)
 
 		// Also set size for the note editor
-		editorBoxWidth := int(float64(m.width) * 0.8)
-		editorBoxHeight := int(float64(m.height) * 0.8)
-		hpad := focusedBoxStyle.GetHorizontalPadding()
-		vpad := focusedBoxStyle.GetVerticalPadding()
-		titleHeight := lipgloss.Height(titleStyle.Render("Edit Note"))
-
-		m.noteEditor.SetWidth(editorBoxWidth - hpad)
-		m.noteEditor.SetHeight(editorBoxHeight - vpad - titleHeight)
+		m.resizeNoteEditor()
 	}
 
 	if m.err != nil {
 		return m, tea.Quit
 	}
 
+	// Keep the calendar's fetches and refreshes going behind other screens.
+	if calendarwidget.IsBackgroundMsg(msg) && m.state != stateDashboard && m.state != stateEventDetail {
+		var cmd tea.Cmd
+		m.calendar, cmd = m.calendar.Update(msg, false)
+		return m, cmd
+	}
+
 	switch m.state {
 	case stateEditingNote:
 		return m.updateNoteEditor(msg)
 	case stateExitConfirmation:
 		return m.updateExitConfirmation(msg)
+	case statePassphrasePrompt:
+		return m.updatePassphrasePrompt(msg)
+	case stateDraftRecovery:
+		return m.updateDraftRecovery(msg)
+	case stateEventDetail:
+		return m.updateEventDetail(msg)
 	case stateSetupWeather:
 		return m.updateSetupWeather(msg)
 	case stateSetupCalendar:
 		return m.updateSetupCalendar(msg)
 	case stateDashboard:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			if cmd, handled := m.updateReminderKeys(msg); handled {
+				return m, cmd
+			}
+		}
 		return m.updateDashboard(msg)
 	}
 	return m, nil
 }
 
+// updateReminderKeys snoozes or dismisses the reminder in the banner. It
+// runs before the panels see the key, and reports whether it handled it.
+func (m *model) updateReminderKeys(msg tea.KeyMsg) (tea.Cmd, bool) {
+	m.updateKeybindings()
+	var cmd tea.Cmd
+	switch {
+	case key.Matches(msg, m.keys.SnoozeReminder):
+		cmd = m.calendar.SnoozeReminder()
+	case key.Matches(msg, m.keys.DismissReminder):
+		cmd = m.calendar.DismissReminder()
+	default:
+		return nil, false
+	}
+	m.updateKeybindings()
+	return cmd, true
+}
+
 // --- UPDATE: NOTE EDITOR ---
 func (m model) updateNoteEditor(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
+	if msg, ok := msg.(tea.KeyMsg); ok && m.showOutline {
+		return m.updateOutline(msg)
+	}
+	if m.editorPrompt.kind != promptNone && m.editingSource() {
+		return m.updateEditorPrompt(msg)
+	}
+
 	switch msg := msg.(type) {
+	case splitPreviewMsg:
+		if m.noteEditorMode == noteSplitMode && msg.version == m.splitPreviewVersion {
+			m.renderSplitPreview()
+		}
+		return m, nil
 	case tea.KeyMsg:
 		switch {
+		case key.Matches(msg, m.keys.ShowOutline):
+			m.openOutline()
+			return m, nil
+		case key.Matches(msg, m.keys.ToggleSplit):
+			m.toggleSplitMode()
+			m.updateKeybindings()
+			return m, nil
+		case key.Matches(msg, m.keys.Undo):
+			m.undoEdit()
+			m.hasUnsavedChanges = m.noteEditor.Value() != m.originalContent
+			return m, nil
+		case key.Matches(msg, m.keys.Redo):
+			m.redoEdit()
+			m.hasUnsavedChanges = m.noteEditor.Value() != m.originalContent
+			return m, nil
+		case key.Matches(msg, m.keys.Find):
+			return m, m.openEditorPrompt(promptFind)
+		case key.Matches(msg, m.keys.Replace):
+			return m, m.openEditorPrompt(promptReplacePattern)
+		case key.Matches(msg, m.keys.GoToLine):
+			return m, m.openEditorPrompt(promptGoToLine)
+		case key.Matches(msg, m.keys.AttachFile):
+			return m, m.openEditorPrompt(promptAttach)
+		case key.Matches(msg, m.keys.LineNumbers):
+			m.noteEditor.ShowLineNumbers = !m.noteEditor.ShowLineNumbers
+			return m, nil
 		case key.Matches(msg, m.keys.ToggleEditMode):
 			if m.noteEditorMode == notePreviewMode {
 				// Switch to source mode
@@ -467,16 +734,41 @@ func (m model) updateNoteEditor(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 			// Note: Don't handle 'i' key when in edit mode to avoid typing conflicts
+		case key.Matches(msg, m.keys.NextCheckbox):
+			m.moveChecklistCursor(1)
+			return m, nil
+		case key.Matches(msg, m.keys.PrevCheckbox):
+			m.moveChecklistCursor(-1)
+			return m, nil
+		case key.Matches(msg, m.keys.NextImage):
+			m.moveImageCursor(1)
+			return m, nil
+		case key.Matches(msg, m.keys.PrevImage):
+			m.moveImageCursor(-1)
+			return m, nil
+		case key.Matches(msg, m.keys.OpenImage):
+			if err := m.openFocusedImage(); err != nil {
+				m.saveMessage = "⚠️ " + err.Error()
+				m.saveMessageTimer = 3
+			}
+			return m, tickCmd()
+		case key.Matches(msg, m.keys.ToggleCheckbox):
+			if err := m.toggleFocusedCheckbox(); err != nil {
+				m.saveMessage = "⚠️ " + err.Error()
+				m.saveMessageTimer = 3
+			}
+			return m, tickCmd()
 		case key.Matches(msg, m.keys.SaveNote):
-			if m.noteEditorMode == noteSourceMode {
+			if m.editingSource() {
 				content := m.noteEditor.Value()
-				err := os.WriteFile(m.editingNotePath, []byte(content), 0644)
+				err := m.writeNote(content)
 				if err != nil {
 					m.err = fmt.Errorf("could not save note: %w", err)
 					return m, nil
 				}
 				m.noteContent = content
 				m.notes = m.notes.Reload()
+				m.discardDraft()
 				
 				// Show save confirmation message
 				m.saveMessage = "✅ Note saved!"
@@ -485,19 +777,11 @@ func (m model) updateNoteEditor(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.originalContent = content // Update original content
 				
 				// Update preview after saving
-				if m.markdownRenderer != nil {
-					rendered, err := m.markdownRenderer.Render(content)
-					if err != nil {
-						rendered = content
-					}
-					m.noteViewer.SetContent(rendered)
-				} else {
-					m.noteViewer.SetContent(content)
-				}
+				m.renderNotePreview()
 			}
 			return m, tickCmd()
 		case key.Matches(msg, m.keys.Cancel):
-			if m.noteEditorMode == noteSourceMode {
+			if m.editingSource() {
 				// If in source mode, check for unsaved changes before going to preview
 				currentContent := m.noteEditor.Value()
 				hasChanges := currentContent != m.originalContent
@@ -514,23 +798,17 @@ func (m model) updateNoteEditor(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.noteEditorMode = notePreviewMode
 					m.noteContent = currentContent
 					m.noteEditor.Blur()
+					m.resizeNoteEditor()
 					
 					// Update the preview
-					if m.markdownRenderer != nil {
-						rendered, err := m.markdownRenderer.Render(m.noteContent)
-						if err != nil {
-							rendered = m.noteContent
-						}
-						m.noteViewer.SetContent(rendered)
-					} else {
-						m.noteViewer.SetContent(m.noteContent)
-					}
+					m.renderNotePreview()
 					m.updateKeybindings()
 					return m, nil
 				}
 			} else {
 				// If in preview mode, exit directly (no confirmation needed here)
 				m.state = stateDashboard
+				m.notePassphrase = "" // Forget the key of encrypted notes
 				m.noteEditor.Blur()
 				m.updateKeybindings()
 				return m, nil
@@ -539,11 +817,25 @@ func (m model) updateNoteEditor(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	// Update the appropriate component based on mode
-	if m.noteEditorMode == noteSourceMode {
-		m.noteEditor, cmd = m.noteEditor.Update(msg)
+	if m.editingSource() {
+		before := captureSnapshot(m.noteEditor)
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.Type == tea.KeyEnter && m.continueList() {
+			cmd = nil
+		} else {
+			m.noteEditor, cmd = m.noteEditor.Update(msg)
+		}
+		if m.noteEditor.Value() != before.value {
+			m.editHistory.record(before, isTypingKey(msg))
+		}
 		// Check for unsaved changes
 		currentContent := m.noteEditor.Value()
 		m.hasUnsavedChanges = currentContent != m.originalContent
+		if m.noteEditorMode == noteSplitMode {
+			if currentContent != before.value {
+				cmd = tea.Batch(cmd, m.scheduleSplitPreview())
+			}
+			m.syncSplitScroll()
+		}
 	} else {
 		m.noteViewer, cmd = m.noteViewer.Update(msg)
 	}
@@ -551,6 +843,71 @@ func (m model) updateNoteEditor(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// openNoteEditor switches to the note editor in preview mode for the given note.
+func (m *model) openNoteEditor(path, content string) {
+	m.state = stateEditingNote
+	m.editingNotePath = path
+	m.noteContent = content
+	m.originalContent = m.noteContent // Save original for comparison
+	m.hasUnsavedChanges = false
+	m.noteEditor.SetValue(m.noteContent)
+	m.noteEditorMode = notePreviewMode
+
+	// Initialize preview
+	m.checklistCursor = 0
+	m.imageCursor = 0
+	m.showOutline = false
+	m.editorPrompt = editorPrompt{}
+	m.editHistory.reset()
+	m.resizeNoteEditor()
+	m.renderNotePreview()
+
+	m.updateKeybindings()
+	m.checkForDraft()
+}
+
+// noteContentSize returns the size of the note editor box's content area,
+// excluding the outline panel.
+func (m model) noteContentSize() (width, height int) {
+	editorBoxWidth := int(float64(m.width) * 0.8)
+	editorBoxHeight := int(float64(m.height) * 0.8)
+	titleHeight := 1 // Title takes 1 line
+
+	width = editorBoxWidth - focusedBoxStyle.GetHorizontalPadding()
+	height = editorBoxHeight - focusedBoxStyle.GetVerticalPadding() - titleHeight - 2 // Extra space for mode indicator
+	if m.showOutline {
+		width -= outlineWidth + 3 // Outline border and gap
+	}
+	return width, height
+}
+
+// resizeNoteEditor fits the textarea and the preview viewport to the editor
+// box, or to a pane each in split mode. It must be called whenever the window
+// size, the split mode or the outline panel changes.
+func (m *model) resizeNoteEditor() {
+	width, height := m.noteContentSize()
+	if m.noteEditorMode == noteSplitMode {
+		width = m.splitPaneWidth()
+	}
+	m.noteEditor.SetWidth(width)
+	m.noteEditor.SetHeight(height)
+	m.noteViewer.Width = width
+	m.noteViewer.Height = height
+}
+
+// writeNote saves content to the note being edited, encrypting it again if
+// the note was unlocked with a passphrase.
+func (m model) writeNote(content string) error {
+	if m.notePassphrase == "" {
+		return os.WriteFile(m.editingNotePath, []byte(content), 0644)
+	}
+	sealed, err := notes.EncryptNote([]byte(content), m.notePassphrase)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.editingNotePath, sealed, 0600)
+}
+
 // --- UPDATE: EXIT CONFIRMATION ---
 func (m model) updateExitConfirmation(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -568,21 +925,16 @@ func (m model) updateExitConfirmation(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.confirmationChoice == 0 { // Yes - Continue without saving
 				// Go to preview mode without saving changes
 				m.noteEditorMode = notePreviewMode
+				m.resizeNoteEditor()
 				m.noteContent = m.originalContent // Restore original content
 				m.noteEditor.SetValue(m.originalContent) // Reset editor
+				m.editHistory.reset()
+				m.discardDraft()
 				m.hasUnsavedChanges = false
 				m.noteEditor.Blur()
 				
 				// Update the preview with original content
-				if m.markdownRenderer != nil {
-					rendered, err := m.markdownRenderer.Render(m.originalContent)
-					if err != nil {
-						rendered = m.originalContent
-					}
-					m.noteViewer.SetContent(rendered)
-				} else {
-					m.noteViewer.SetContent(m.originalContent)
-				}
+				m.renderNotePreview()
 				
 				m.state = stateEditingNote
 				m.updateKeybindings()
@@ -615,20 +967,8 @@ func (m model) updateSetupWeather(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if city != "" {
 				m.settings.Location = city
 				if err := config.SaveSettings(m.settings); err == nil {
-					if !calendarwidget.IsAuthorized() {
-						m.state = stateSetupCalendar
-						authURL, err := calendarwidget.GetAuthURL()
-						if err != nil {
-							m.err = err
-						}
-						m.calendarAuthURL = authURL
-						m.setupTextInput.Reset()
-						if calendarwidget.IsUsingManualFlow() {
-			m.setupTextInput.Placeholder = "Paste authorization code here..."
-		} else {
-			m.setupTextInput.Placeholder = "Authorization will complete automatically..."
-		}
-						m.setupTextInput.Focus()
+					if account, ok := m.unauthorizedAccount(); ok {
+						m.startCalendarAuth(account)
 						return m, textinput.Blink
 					} else {
 						m.state = stateDashboard
@@ -643,49 +983,137 @@ func (m model) updateSetupWeather(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// startCalendarAuth shows the authorization screen for a Google account.
+func (m *model) startCalendarAuth(account string) {
+	m.state = stateSetupCalendar
+	m.calendarAccount = account
+	m.calendarDevice = nil
+	authURL, err := calendarwidget.GetAuthURL(account)
+	m.calendarAuthErr = err
+	m.calendarAuthURL = authURL
+	m.setupTextInput.Reset()
+	if calendarwidget.IsUsingManualFlow() {
+		m.setupTextInput.Placeholder = "Paste the address from your browser here..."
+	} else {
+		m.setupTextInput.Placeholder = "Authorization will complete automatically..."
+	}
+	m.setupTextInput.Focus()
+}
+
+// errGoogleSkipped is shown in the calendar panel when the authorization of
+// the Google calendars was skipped.
+var errGoogleSkipped = errors.New("Google Calendar is not connected, restart GoDash to authorize it")
+
+// unauthorizedAccount returns the first Google account that still needs
+// authorizing, unless the Google calendars are disabled for this session.
+func (m *model) unauthorizedAccount() (string, bool) {
+	if m.googleDisabled {
+		return "", false
+	}
+	return calendarwidget.UnauthorizedAccount(m.settings.Calendars)
+}
+
+// disableGoogle leaves the Google calendars out for the rest of the session,
+// showing reason in the calendar panel, so notes and todos stay usable when
+// GoDash can't or shouldn't sign in to Google.
+func (m *model) disableGoogle(reason error) {
+	m.googleDisabled = true
+	calendarwidget.CancelAuth()
+	m.calendar.DisableGoogle(reason)
+}
+
+// finishCalendarAuth moves on to the next Google account that needs
+// authorizing, or to the dashboard once all of them are.
+func (m *model) finishCalendarAuth() tea.Cmd {
+	if account, ok := m.unauthorizedAccount(); ok {
+		m.startCalendarAuth(account)
+		return textinput.Blink
+	}
+	m.state = stateDashboard
+	m.updateKeybindings()
+	return m.calendar.Init()
+}
+
 func (m model) updateSetupCalendar(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	
 	// Check if auth is complete first
-	if calendarwidget.IsAuthorized() {
-		m.state = stateDashboard
-		m.updateKeybindings()
-		return m, m.calendar.Init()
+	if calendarwidget.IsAuthorized(m.calendarAccount) {
+		cmd := m.finishCalendarAuth()
+		return m, cmd
 	}
 	
 	switch msg := msg.(type) {
+	case calendarDeviceMsg:
+		if msg.err != nil {
+			m.calendarAuthErr = msg.err
+			return m, nil
+		}
+		m.calendarDevice = &msg.code
+		m.calendarAuthErr = nil
+		m.setupTextInput.Blur()
+		return m, waitForCalendarAuth
+	case calendarAuthDoneMsg:
+		if errors.Is(msg.err, calendarwidget.ErrAuthRestarted) {
+			// A flow the user switched away from
+			return m, nil
+		}
+		if msg.err != nil {
+			// Start over so the next attempt gets a fresh link
+			m.startCalendarAuth(m.calendarAccount)
+			m.calendarAuthErr = msg.err
+		}
+		return m, nil
 	case tea.KeyMsg:
 		switch {
 		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
+		case key.Matches(msg, m.keys.Cancel):
+			// Go on without the Google calendars
+			m.disableGoogle(errGoogleSkipped)
+			cmd := m.finishCalendarAuth()
+			return m, cmd
+		case key.Matches(msg, m.keys.DeviceCode):
+			if m.calendarDevice != nil {
+				// Back to signing in with the browser
+				m.startCalendarAuth(m.calendarAccount)
+				return m, textinput.Blink
+			}
+			return m, startCalendarDeviceAuth(m.calendarAccount)
+		case key.Matches(msg, m.keys.ShowQRCode):
+			m.showDeviceQR = !m.showDeviceQR
+			return m, nil
 		case key.Matches(msg, m.keys.OpenLink):
+			if m.calendarDevice != nil {
+				_ = openURLInBrowser(m.calendarDevice.VerificationURL)
+				break
+			}
+			if m.calendarAuthURL == "" {
+				break
+			}
 			_ = openURLInBrowser(m.calendarAuthURL)
 			if !calendarwidget.IsUsingManualFlow() {
-				// Start waiting for auth completion in background for automatic flow
-				go func() {
-					calendarwidget.WaitForAuth()
-				}()
+				// Wait for the browser to come back to the callback server
+				return m, waitForCalendarAuth
 			}
 		case key.Matches(msg, m.keys.Confirm):
 			if calendarwidget.IsUsingManualFlow() {
 				// Manual flow - get code from text input
 				authCode := m.setupTextInput.Value()
 				if authCode != "" {
-					err := calendarwidget.CompleteAuth(authCode)
+					err := calendarwidget.CompleteAuth(m.calendarAccount, authCode)
 					if err == nil {
-						m.state = stateDashboard
-						m.updateKeybindings()
-						return m, m.calendar.Init()
+						cmd := m.finishCalendarAuth()
+						return m, cmd
 					} else {
-						m.err = err
+						m.calendarAuthErr = err
 					}
 				}
 			} else {
 				// Automatic flow - just check if auth is complete
-				if calendarwidget.IsAuthorized() {
-					m.state = stateDashboard
-					m.updateKeybindings()
-					return m, m.calendar.Init()
+				if calendarwidget.IsAuthorized(m.calendarAccount) {
+					cmd := m.finishCalendarAuth()
+					return m, cmd
 				}
 			}
 		}
@@ -701,33 +1129,38 @@ func (m model) updateDashboard(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	if m.todo.GetState() == todo.ListStateAdding || m.todo.GetState() == todo.ListStateEditing {
+		if calendarwidget.IsBackgroundMsg(msg) {
+			m.calendar, cmd = m.calendar.Update(msg, false)
+			return m, cmd
+		}
 		m.todo, cmd = m.todo.Update(msg, m.focus == focusList)
 		cmds = append(cmds, cmd)
 		return m, tea.Batch(cmds...)
 	}
 
+	// The event form uses tab, q and enter itself.
+	if m.calendar.IsEditing() {
+		m.calendar, cmd = m.calendar.Update(msg, m.focus == focusCalendar)
+		m.updateKeybindings()
+		return m, cmd
+	}
+
 	switch msg := msg.(type) {
 	case notes.EditNoteMsg:
-		m.state = stateEditingNote
-		m.editingNotePath = msg.Path
-		m.noteContent = string(msg.Content)
-		m.originalContent = m.noteContent // Save original for comparison
-		m.hasUnsavedChanges = false
-		m.noteEditor.SetValue(m.noteContent)
-		m.noteEditorMode = notePreviewMode
-		
-		// Initialize preview
-		if m.markdownRenderer != nil {
-			rendered, err := m.markdownRenderer.Render(m.noteContent)
-			if err != nil {
-				rendered = m.noteContent
-			}
-			m.noteViewer.SetContent(rendered)
-		} else {
-			m.noteViewer.SetContent(m.noteContent)
+		if msg.Encrypted {
+			return m, m.startPassphrasePrompt(passphraseOpen, msg.Path, msg.Content)
 		}
-		
-		m.updateKeybindings()
+		m.notePassphrase = ""
+		m.openNoteEditor(msg.Path, string(msg.Content))
+		return m, nil
+	case notes.ToggleEncryptionMsg:
+		purpose := passphraseEncrypt
+		if msg.Encrypted {
+			purpose = passphraseDecrypt
+		}
+		return m, m.startPassphrasePrompt(purpose, msg.Path, nil)
+	case notes.PromoteToTodoMsg:
+		m.todo.AddTask(msg.Title)
 		return m, nil
 	case tea.MouseMsg:
 		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
@@ -751,11 +1184,18 @@ func (m model) updateDashboard(msg tea.Msg) (tea.Model, tea.Cmd) {
 	cmds = append(cmds, cmd)
 	m.calendar, cmd = m.calendar.Update(msg, m.focus == focusCalendar)
 	cmds = append(cmds, cmd)
+	m.updateKeybindings()
 
 	if msg, ok := msg.(tea.KeyMsg); ok {
 		if m.focus == focusCalendar && key.Matches(msg, m.keys.OpenCalendar) {
 			_ = openURLInBrowser("https://calendar.google.com/calendar/u/0/r")
 		}
+		if m.focus == focusCalendar && key.Matches(msg, m.keys.ShowEvent) {
+			if event, ok := m.calendar.SelectedEvent(); ok {
+				m.openEventDetail(event)
+				return m, nil
+			}
+		}
 
 		if msg.String() == "q" {
 			return m, nil
@@ -823,6 +1263,12 @@ func (m model) View() string {
 		return m.viewNoteEditor()
 	case stateExitConfirmation:
 		return m.viewExitConfirmation()
+	case statePassphrasePrompt:
+		return m.viewPassphrasePrompt()
+	case stateDraftRecovery:
+		return m.viewDraftRecovery()
+	case stateEventDetail:
+		return m.viewEventDetail()
 	case stateSetupWeather, stateSetupCalendar:
 		return m.viewSetup()
 	case stateDashboard:
@@ -835,18 +1281,7 @@ func (m model) View() string {
 func (m model) viewNoteEditor() string {
 	editorBoxWidth := int(float64(m.width) * 0.8)
 	editorBoxHeight := int(float64(m.height) * 0.8)
-
-	// Update viewport dimensions to match editor box
-	hpad := focusedBoxStyle.GetHorizontalPadding()
-	vpad := focusedBoxStyle.GetVerticalPadding()
-	titleHeight := 1 // Title takes 1 line
-	
-	m.noteViewer.Width = editorBoxWidth - hpad
-	m.noteViewer.Height = editorBoxHeight - vpad - titleHeight - 2 // Extra space for mode indicator
-	
-	// Update textarea dimensions as well
-	m.noteEditor.SetWidth(editorBoxWidth - hpad)
-	m.noteEditor.SetHeight(editorBoxHeight - vpad - titleHeight - 2)
+	_, contentHeight := m.noteContentSize()
 
 	var title string
 	var content string
@@ -854,9 +1289,27 @@ func (m model) viewNoteEditor() string {
 	if m.noteEditorMode == notePreviewMode {
 		title = titleStyle.Render("Note Preview (press 'i' to edit)")
 		content = m.noteViewer.View()
+		if status := m.checklistStatus(); status != "" {
+			content = lipgloss.JoinVertical(lipgloss.Left, status, content)
+		}
+		if status := m.imageStatus(); status != "" {
+			content = lipgloss.JoinVertical(lipgloss.Left, status, content)
+		}
+	} else if m.noteEditorMode == noteSplitMode {
+		title = titleStyle.Render("Edit Note with Live Preview (ctrl+x to close preview)")
+		content = m.viewSplit(contentHeight)
+		if prompt := m.viewEditorPrompt(); prompt != "" {
+			content = lipgloss.JoinVertical(lipgloss.Left, content, prompt)
+		}
 	} else {
 		title = titleStyle.Render("Edit Note (press 'i' to preview)")
 		content = m.noteEditor.View()
+		if prompt := m.viewEditorPrompt(); prompt != "" {
+			content = lipgloss.JoinVertical(lipgloss.Left, content, prompt)
+		}
+	}
+	if m.showOutline {
+		content = lipgloss.JoinHorizontal(lipgloss.Top, content, " ", m.viewOutline(contentHeight))
 	}
 	
 	// Add save message if present
@@ -920,15 +1373,45 @@ func (m model) viewSetup() string {
 	case stateSetupCalendar:
 		title = "📅 Calendar Authorization"
 		mainPrompt = "Connect your Google Calendar"
+		if m.calendarAccount != "" {
+			mainPrompt = fmt.Sprintf("Connect the Google account %q", m.calendarAccount)
+		}
 		
-		if calendarwidget.IsUsingManualFlow() {
+		useCode := "    " + yellowText.Render("Ctrl+T") + " Use a Code"
+		if m.calendarDevice != nil {
+			lines := []string{
+				"Visit " + blueText.Render(m.calendarDevice.VerificationURL),
+				"on any device and enter the code",
+				"",
+				lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#e5c07b")).Render(m.calendarDevice.UserCode),
+			}
+			if m.showDeviceQR {
+				if qr, err := calendarwidget.DeviceQRCode(m.calendarDevice.VerificationURL); err == nil {
+					lines = append(lines, "", qr)
+				}
+			}
+			inputSection = lipgloss.JoinVertical(lipgloss.Center, lines...)
+			instructions = fmt.Sprintf("⏳ Waiting for approval, the code expires at %s", m.calendarDevice.Expires.Local().Format("15:04"))
+			keybinds = yellowText.Render("Ctrl+R") + " QR Code    " + yellowText.Render("Ctrl+T") + " Use the Browser"
+		} else if m.calendarAuthURL == "" {
+			inputSection = ""
+			instructions = ""
+			keybinds = yellowText.Render("Ctrl+Q") + " Quit"
+		} else if calendarwidget.IsUsingManualFlow() {
 			inputSection = m.setupTextInput.View()
-			instructions = "📋 After authorization, paste the code here"
-			keybinds = yellowText.Render("Ctrl+O") + " Authorize    " + yellowText.Render("Enter") + " Submit Code"
+			instructions = "📋 After authorization, paste the address your browser was sent to here"
+			keybinds = yellowText.Render("Ctrl+O") + " Authorize    " + yellowText.Render("Enter") + " Submit" + useCode
 		} else {
 			inputSection = ""
 			instructions = ""
-			keybinds = yellowText.Render("Ctrl+O") + " Authorize"
+			if os.Getenv("SSH_CONNECTION") != "" {
+				instructions = "💡 No browser in this session? Sign in from another device with a code"
+			}
+			keybinds = yellowText.Render("Ctrl+O") + " Authorize" + useCode
+		}
+		keybinds += "\n" + yellowText.Render("Esc") + " Continue without Google Calendar"
+		if m.calendarAuthErr != nil {
+			instructions = redText.Width(52).Align(lipgloss.Center).Render(fmt.Sprintf("⚠ %v", m.calendarAuthErr))
 		}
 	}
 
@@ -972,6 +1455,15 @@ func (m model) viewSetup() string {
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
 }
 
+// reminderBanner shows the first due event reminder with the keys to
+// snooze or dismiss it.
+func (m model) reminderBanner() string {
+	hint := yellowText.Render(m.keys.SnoozeReminder.Help().Key) + " snooze · " +
+		yellowText.Render(m.keys.DismissReminder.Help().Key) + " dismiss"
+	text := ansi.Truncate("🔔 "+m.calendar.ReminderText(), max(m.width-lipgloss.Width(hint)-4, 1), "…")
+	return reminderStyle.Render(text) + "   " + hint
+}
+
 func (m model) viewDashboard() string {
 	// Add logo at the top
 	logo := renderAppLogo()
@@ -1041,8 +1533,11 @@ func (m model) viewDashboard() string {
 		rightStatus,
 	)
 
-	// Center the logo and combine everything
+	// Center the logo and combine everything. A due reminder takes its place.
 	centeredLogo := lipgloss.Place(m.width, logoHeight, lipgloss.Center, lipgloss.Center, logo)
+	if m.calendar.HasReminder() {
+		centeredLogo = lipgloss.Place(m.width, logoHeight, lipgloss.Center, lipgloss.Center, m.reminderBanner())
+	}
 	
 	return lipgloss.JoinVertical(lipgloss.Top, centeredLogo, grid, statusBar)
 }
@@ -1059,7 +1554,23 @@ func main() {
 		os.Exit(1)
 	}
 
-	p := tea.NewProgram(initialModel(settings), tea.WithAltScreen(), tea.WithMouseAllMotion())
+	if handled, err := runSubcommand(os.Args[1:]); handled {
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Move CalDAV passwords out of config.json before the app holds the
+	// settings, so saving them later doesn't write the passwords back.
+	migrateErr := calendarwidget.MigrateCalDAVPasswords(&settings)
+	m := initialModel(settings)
+	if migrateErr != nil {
+		m.calendar.ShowError(migrateErr)
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseAllMotion())
 	if _, err := p.Run(); err != nil {
 		fmt.Println("Error running program:", err)
 		os.Exit(1)
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"GoDash/widgets/notes"
+)
+
+var imageStatusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#e5c07b"))
+
+// withImagePlaceholders replaces the images of content, which terminals can't
+// show, with numbered placeholders that can be opened in the system viewer.
+func withImagePlaceholders(content string) (string, []notes.ImageRef) {
+	return notes.ReplaceImages(content, func(i int, img notes.ImageRef) string {
+		return fmt.Sprintf("`🖼 image %d: %s`", i+1, img.Alt)
+	})
+}
+
+// moveImageCursor focuses the next (delta > 0) or previous image and scrolls
+// the preview to its placeholder.
+func (m *model) moveImageCursor(delta int) {
+	if len(m.noteImages) == 0 {
+		return
+	}
+	m.imageCursor = (m.imageCursor + delta + len(m.noteImages)) % len(m.noteImages)
+	m.scrollPreviewTo(fmt.Sprintf("image %d:", m.imageCursor+1), 0)
+}
+
+// openFocusedImage opens the focused image with the system viewer.
+func (m *model) openFocusedImage() error {
+	if len(m.noteImages) == 0 {
+		return nil
+	}
+	img := m.noteImages[m.imageCursor]
+	target, err := notes.ResolveAttachment(m.editingNotePath, img.Target)
+	if err != nil {
+		return err
+	}
+	if err := openURLInBrowser(target); err != nil {
+		return fmt.Errorf("could not open %s: %w", img.Target, err)
+	}
+	m.saveMessage = "🖼 Opening " + img.Alt
+	m.saveMessageTimer = 3
+	return nil
+}
+
+// attachFile copies path into the note's attachments folder and inserts a
+// link to it at the cursor.
+func (m *model) attachFile(path string) error {
+	link, err := notes.AttachFile(m.editingNotePath, path)
+	if err != nil {
+		return err
+	}
+	m.editHistory.record(captureSnapshot(m.noteEditor), false)
+	m.noteEditor.InsertString(link)
+	m.hasUnsavedChanges = m.noteEditor.Value() != m.originalContent
+	return nil
+}
+
+// imageStatus describes the focused image in preview mode.
+func (m model) imageStatus() string {
+	if len(m.noteImages) == 0 {
+		return ""
+	}
+	img := m.noteImages[m.imageCursor]
+	status := fmt.Sprintf("🖼 %s (%d/%d)", img.Alt, m.imageCursor+1, len(m.noteImages))
+	hint := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  o open · { } move")
+	maxWidth := m.noteViewer.Width - lipgloss.Width(hint)
+	if maxWidth > 0 && lipgloss.Width(status) > maxWidth {
+		status = ansi.Truncate(status, maxWidth, "…")
+	}
+	return imageStatusStyle.Render(status) + hint
+}
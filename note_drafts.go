@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"GoDash/widgets/notes"
+)
+
+// draftInterval is how often unsaved note edits are written to the drafts dir.
+const draftInterval = 5 * time.Second
+
+// draftTickMsg triggers an autosave of the note being edited.
+type draftTickMsg time.Time
+
+func draftTickCmd() tea.Cmd {
+	return tea.Tick(draftInterval, func(t time.Time) tea.Msg {
+		return draftTickMsg(t)
+	})
+}
+
+// draftRecovery holds the state of stateDraftRecovery.
+type draftRecovery struct {
+	content string
+	savedAt time.Time
+	diff    viewport.Model
+	choice  int // 0 = Recover, 1 = Discard
+}
+
+var (
+	diffInsertStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#98c379"))
+	diffDeleteStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#e06c75"))
+	diffEqualStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#5c6370"))
+)
+
+// autosaveDraft writes the editor contents to the drafts dir while there are
+// unsaved changes. Drafts of encrypted notes are encrypted with the same key.
+func (m *model) autosaveDraft() {
+	if m.state != stateEditingNote && m.state != stateExitConfirmation {
+		return
+	}
+	content := m.noteEditor.Value()
+	if !m.hasUnsavedChanges || content == m.lastDraft {
+		return
+	}
+
+	data := []byte(content)
+	if m.notePassphrase != "" {
+		sealed, err := notes.EncryptNote(data, m.notePassphrase)
+		if err != nil {
+			return
+		}
+		data = sealed
+	}
+	if err := notes.SaveDraft(m.editingNotePath, data); err == nil {
+		m.lastDraft = content
+	}
+}
+
+// discardDraft removes the draft of the note being edited, typically because
+// its changes were saved or deliberately thrown away.
+func (m *model) discardDraft() {
+	notes.DeleteDraft(m.editingNotePath)
+	m.lastDraft = ""
+}
+
+// checkForDraft offers to recover an autosaved draft of the just opened note
+// if it differs from the saved content.
+func (m *model) checkForDraft() {
+	m.lastDraft = ""
+	data, savedAt, err := notes.LoadDraft(m.editingNotePath)
+	if err != nil {
+		return
+	}
+	if m.notePassphrase != "" {
+		if data, err = notes.DecryptNote(data, m.notePassphrase); err != nil {
+			return
+		}
+	}
+	content := string(data)
+	if content == m.originalContent {
+		m.discardDraft()
+		return
+	}
+
+	var b strings.Builder
+	for _, line := range notes.LineDiff(m.originalContent, content) {
+		switch line.Op {
+		case notes.DiffInsert:
+			b.WriteString(diffInsertStyle.Render("+ "+line.Text) + "\n")
+		case notes.DiffDelete:
+			b.WriteString(diffDeleteStyle.Render("- "+line.Text) + "\n")
+		default:
+			b.WriteString(diffEqualStyle.Render("  "+line.Text) + "\n")
+		}
+	}
+	vp := viewport.New(72, min(15, max(5, m.height/2)))
+	vp.SetContent(strings.TrimSuffix(b.String(), "\n"))
+
+	m.draftRecovery = draftRecovery{content: content, savedAt: savedAt, diff: vp}
+	m.state = stateDraftRecovery
+	m.updateKeybindings()
+}
+
+// --- UPDATE: DRAFT RECOVERY ---
+func (m model) updateDraftRecovery(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	r := &m.draftRecovery
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("left", "h", "r", "R"))):
+			r.choice = 0 // Recover
+			return m, nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("right", "l", "d", "D"))):
+			r.choice = 1 // Discard
+			return m, nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+			// Open the saved note but keep the draft for later.
+			m.draftRecovery = draftRecovery{}
+			m.state = stateEditingNote
+			m.updateKeybindings()
+			return m, nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+			if r.choice == 0 {
+				m.noteContent = r.content
+				m.noteEditor.SetValue(r.content)
+				m.renderNotePreview()
+				m.noteEditorMode = noteSourceMode
+				m.noteEditor.Focus()
+				m.hasUnsavedChanges = true
+				m.lastDraft = r.content
+			} else {
+				m.discardDraft()
+			}
+			m.draftRecovery = draftRecovery{}
+			m.state = stateEditingNote
+			m.updateKeybindings()
+			return m, nil
+		}
+	}
+
+	r.diff, cmd = r.diff.Update(msg)
+	return m, cmd
+}
+
+// --- VIEW: DRAFT RECOVERY ---
+func (m model) viewDraftRecovery() string {
+	r := m.draftRecovery
+	title := "📝 Unsaved Draft Found"
+	message := fmt.Sprintf("A draft of this note was autosaved on %s.\nRecover it? Changes against the saved note:", r.savedAt.Format("Jan 2 15:04"))
+
+	recoverStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#abb2bf")).Padding(0, 1)
+	discardStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#abb2bf")).Padding(0, 1)
+	if r.choice == 0 {
+		recoverStyle = recoverStyle.Background(lipgloss.Color("#98c379")).Foreground(lipgloss.Color("#ffffff")).Bold(true)
+	} else {
+		discardStyle = discardStyle.Background(lipgloss.Color("#e06c75")).Foreground(lipgloss.Color("#ffffff")).Bold(true)
+	}
+	buttons := lipgloss.JoinHorizontal(lipgloss.Left, recoverStyle.Render("Recover"), "  ", discardStyle.Render("Discard"))
+
+	instructions := "Use ←/→ or R/D to choose, ↑/↓ to scroll, Enter to confirm, Esc to decide later"
+
+	content := lipgloss.JoinVertical(lipgloss.Center,
+		orangeText.Render(title),
+		"",
+		message,
+		"",
+		lipgloss.NewStyle().Border(lipgloss.NormalBorder()).BorderForeground(lipgloss.Color("#4b5263")).Render(r.diff.View()),
+		"",
+		buttons,
+		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("#7c7c7c")).Render(instructions),
+	)
+
+	dialogBox := helpBoxStyle.Width(80).Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, dialogBox)
+}
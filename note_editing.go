@@ -0,0 +1,328 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- UNDO / REDO ---
+
+// editSnapshot is a textarea state that can be restored by undo or redo.
+type editSnapshot struct {
+	value     string
+	line, col int
+}
+
+// editHistory keeps the undo and redo stacks of the note source editor.
+type editHistory struct {
+	undo       []editSnapshot
+	redo       []editSnapshot
+	lastRecord time.Time
+}
+
+// maxEditHistory bounds memory use for long editing sessions.
+const maxEditHistory = 500
+
+// record pushes the state from before an edit. Consecutive typing within a
+// second is coalesced into a single undo step when coalesce is set.
+func (h *editHistory) record(snap editSnapshot, coalesce bool) {
+	now := time.Now()
+	h.redo = nil
+	if coalesce && len(h.undo) > 0 && now.Sub(h.lastRecord) < time.Second {
+		h.lastRecord = now
+		return
+	}
+	h.lastRecord = now
+	h.undo = append(h.undo, snap)
+	if len(h.undo) > maxEditHistory {
+		h.undo = h.undo[1:]
+	}
+}
+
+func (h *editHistory) reset() {
+	*h = editHistory{}
+}
+
+func captureSnapshot(ta textarea.Model) editSnapshot {
+	info := ta.LineInfo()
+	return editSnapshot{value: ta.Value(), line: ta.Line(), col: info.StartColumn + info.ColumnOffset}
+}
+
+func restoreSnapshot(ta *textarea.Model, snap editSnapshot) {
+	ta.SetValue(snap.value)
+	moveEditorToLine(ta, snap.line)
+	ta.SetCursor(snap.col)
+}
+
+// undoEdit restores the state before the last edit.
+func (m *model) undoEdit() {
+	if len(m.editHistory.undo) == 0 {
+		return
+	}
+	last := len(m.editHistory.undo) - 1
+	snap := m.editHistory.undo[last]
+	m.editHistory.undo = m.editHistory.undo[:last]
+	m.editHistory.redo = append(m.editHistory.redo, captureSnapshot(m.noteEditor))
+	restoreSnapshot(&m.noteEditor, snap)
+	// Typing right after an undo must start a new undo step.
+	m.editHistory.lastRecord = time.Time{}
+}
+
+// redoEdit reapplies the last undone edit.
+func (m *model) redoEdit() {
+	if len(m.editHistory.redo) == 0 {
+		return
+	}
+	last := len(m.editHistory.redo) - 1
+	snap := m.editHistory.redo[last]
+	m.editHistory.redo = m.editHistory.redo[:last]
+	m.editHistory.undo = append(m.editHistory.undo, captureSnapshot(m.noteEditor))
+	restoreSnapshot(&m.noteEditor, snap)
+	m.editHistory.lastRecord = time.Time{}
+}
+
+// isTypingKey reports whether msg inserts a single non-space character, the
+// kind of edit that is coalesced in the undo history.
+func isTypingKey(msg tea.Msg) bool {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	return ok && keyMsg.Type == tea.KeyRunes && len(keyMsg.Runes) == 1 && keyMsg.Runes[0] != ' '
+}
+
+// --- LIST CONTINUATION ---
+
+var listItemRegex = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])(\s+)(\[[ xX]\]\s+)?(.*)$`)
+
+// continueList handles Enter on a markdown list item: it starts the next item
+// with the same bullet (incrementing numbers and resetting checkboxes), or
+// ends the list when the current item is empty. Enter with the cursor in or
+// before the marker is left to the textarea. It reports whether Enter was
+// handled.
+func (m *model) continueList() bool {
+	lines := strings.Split(m.noteEditor.Value(), "\n")
+	row := m.noteEditor.Line()
+	if row >= len(lines) {
+		return false
+	}
+	match := listItemRegex.FindStringSubmatch(lines[row])
+	if match == nil {
+		return false
+	}
+	indent, bullet, gap, checkbox, text := match[1], match[2], match[3], match[4], match[5]
+	if info := m.noteEditor.LineInfo(); info.StartColumn+info.ColumnOffset < utf8.RuneCountInString(indent+bullet+gap+checkbox) {
+		return false
+	}
+
+	if strings.TrimSpace(text) == "" {
+		// Enter on an empty item ends the list.
+		lines[row] = ""
+		m.noteEditor.SetValue(strings.Join(lines, "\n"))
+		moveEditorToLine(&m.noteEditor, row)
+		return true
+	}
+
+	if n, err := strconv.Atoi(strings.TrimRight(bullet, ".)")); err == nil {
+		bullet = strconv.Itoa(n+1) + bullet[len(bullet)-1:]
+	}
+	if checkbox != "" {
+		checkbox = "[ ] "
+	}
+	m.noteEditor.InsertString("\n" + indent + bullet + gap + checkbox)
+	return true
+}
+
+// --- FIND / REPLACE / GO TO LINE ---
+
+type editorPromptKind int
+
+const (
+	promptNone editorPromptKind = iota
+	promptFind
+	promptReplacePattern
+	promptReplaceWith
+	promptGoToLine
+	promptAttach
+)
+
+// editorPrompt is the single-line prompt shown below the source editor.
+type editorPrompt struct {
+	kind    editorPromptKind
+	input   textinput.Model
+	pattern *regexp.Regexp // compiled pattern while asking for a replacement
+	errMsg  string
+}
+
+func (m *model) openEditorPrompt(kind editorPromptKind) tea.Cmd {
+	ti := textinput.New()
+	ti.CharLimit = 200
+	ti.Width = max(20, m.noteEditor.Width()-30)
+	switch kind {
+	case promptFind:
+		ti.Prompt = "Find (regex): "
+		ti.SetValue(m.lastSearch)
+	case promptReplacePattern:
+		ti.Prompt = "Replace (regex): "
+		ti.SetValue(m.lastSearch)
+	case promptGoToLine:
+		ti.Prompt = "Go to line: "
+		ti.CharLimit = 8
+	case promptAttach:
+		ti.Prompt = "Attach file: "
+		ti.CharLimit = 1024
+	}
+	ti.CursorEnd()
+	ti.Focus()
+	m.editorPrompt = editorPrompt{kind: kind, input: ti}
+	return textinput.Blink
+}
+
+// updateEditorPrompt handles keys while a find, replace or go-to-line prompt
+// is open.
+func (m model) updateEditorPrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	p := &m.editorPrompt
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(keyMsg, key.NewBinding(key.WithKeys("esc"))):
+			m.editorPrompt = editorPrompt{}
+			return m, nil
+		case key.Matches(keyMsg, key.NewBinding(key.WithKeys("enter"))):
+			value := p.input.Value()
+			switch p.kind {
+			case promptFind:
+				re, err := regexp.Compile(value)
+				if err != nil {
+					p.errMsg = "Invalid pattern"
+					return m, nil
+				}
+				m.lastSearch = value
+				if !m.findNext(re) {
+					p.errMsg = "No match"
+				} else {
+					p.errMsg = ""
+				}
+				// The prompt stays open so Enter jumps to the next match.
+				return m, nil
+			case promptReplacePattern:
+				re, err := regexp.Compile(value)
+				if err != nil {
+					p.errMsg = "Invalid pattern"
+					return m, nil
+				}
+				m.lastSearch = value
+				p.kind = promptReplaceWith
+				p.pattern = re
+				p.errMsg = ""
+				p.input.Prompt = "Replace with ($1 for groups): "
+				p.input.Reset()
+				return m, nil
+			case promptReplaceWith:
+				count := m.replaceAll(p.pattern, value)
+				m.editorPrompt = editorPrompt{}
+				m.saveMessage = fmt.Sprintf("🔁 Replaced %d matches", count)
+				m.saveMessageTimer = 3
+				return m, tickCmd()
+			case promptGoToLine:
+				line, err := strconv.Atoi(strings.TrimSpace(value))
+				if err != nil || line < 1 {
+					p.errMsg = "Enter a line number"
+					return m, nil
+				}
+				moveEditorToLine(&m.noteEditor, line-1)
+				m.editorPrompt = editorPrompt{}
+				return m, nil
+			case promptAttach:
+				if err := m.attachFile(value); err != nil {
+					p.errMsg = err.Error()
+					return m, nil
+				}
+				m.editorPrompt = editorPrompt{}
+				return m, nil
+			}
+		}
+	}
+
+	p.input, cmd = p.input.Update(msg)
+	return m, cmd
+}
+
+// findNext moves the cursor to the next match of re after the cursor,
+// wrapping around to the start of the note.
+func (m *model) findNext(re *regexp.Regexp) bool {
+	value := m.noteEditor.Value()
+	offset := cursorOffset(value, captureSnapshot(m.noteEditor))
+
+	// Start one character past the cursor so repeated searches advance.
+	start := offset
+	if start < len(value) {
+		_, size := utf8.DecodeRuneInString(value[start:])
+		start += size
+	}
+	loc := re.FindStringIndex(value[start:])
+	if loc != nil {
+		loc[0] += start
+	} else {
+		loc = re.FindStringIndex(value)
+	}
+	if loc == nil {
+		return false
+	}
+
+	line := strings.Count(value[:loc[0]], "\n")
+	lineStart := strings.LastIndex(value[:loc[0]], "\n") + 1
+	moveEditorToLine(&m.noteEditor, line)
+	m.noteEditor.SetCursor(utf8.RuneCountInString(value[lineStart:loc[0]]))
+	return true
+}
+
+// replaceAll replaces every match of re in the note as a single undo step.
+func (m *model) replaceAll(re *regexp.Regexp, replacement string) int {
+	before := captureSnapshot(m.noteEditor)
+	count := len(re.FindAllStringIndex(before.value, -1))
+	if count == 0 {
+		return 0
+	}
+	m.editHistory.record(before, false)
+	restoreSnapshot(&m.noteEditor, editSnapshot{
+		value: re.ReplaceAllString(before.value, replacement),
+		line:  before.line,
+		col:   before.col,
+	})
+	m.hasUnsavedChanges = m.noteEditor.Value() != m.originalContent
+	return count
+}
+
+// cursorOffset converts a snapshot's line and column into a byte offset.
+func cursorOffset(value string, snap editSnapshot) int {
+	offset := 0
+	for i, line := range strings.Split(value, "\n") {
+		if i == snap.line {
+			runes := []rune(line)
+			return offset + len(string(runes[:min(snap.col, len(runes))]))
+		}
+		offset += len(line) + 1
+	}
+	return len(value)
+}
+
+// viewEditorPrompt renders the open prompt, if any.
+func (m model) viewEditorPrompt() string {
+	if m.editorPrompt.kind == promptNone {
+		return ""
+	}
+	view := m.editorPrompt.input.View()
+	if m.editorPrompt.errMsg != "" {
+		view += "  " + redText.Render(m.editorPrompt.errMsg)
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("#e5c07b")).Render(view)
+}
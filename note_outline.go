@@ -0,0 +1,160 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"GoDash/widgets/notes"
+)
+
+const outlineWidth = 32
+
+var (
+	outlineBoxStyle      = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#4b5263")).Padding(0, 1)
+	outlineSelectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#56b6c2"))
+)
+
+// openOutline shows the heading outline of the open note, preselecting the
+// heading closest above the current position.
+func (m *model) openOutline() {
+	m.outline = notes.ParseOutline(m.currentNoteText())
+	m.outlineCursor = 0
+	if m.editingSource() {
+		for i, h := range m.outline {
+			if h.Line <= m.noteEditor.Line() {
+				m.outlineCursor = i
+			}
+		}
+	}
+	m.showOutline = true
+	m.resizeNoteEditor()
+}
+
+// currentNoteText returns the text the user is looking at: the textarea value
+// in source mode, the saved content in preview mode.
+func (m model) currentNoteText() string {
+	if m.editingSource() {
+		return m.noteEditor.Value()
+	}
+	return m.noteContent
+}
+
+// updateOutline handles keys while the outline panel is open.
+func (m model) updateOutline(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.ShowOutline), key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+		m.showOutline = false
+		m.resizeNoteEditor()
+	case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+		if m.outlineCursor > 0 {
+			m.outlineCursor--
+		}
+	case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+		if m.outlineCursor < len(m.outline)-1 {
+			m.outlineCursor++
+		}
+	case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+		m.showOutline = false
+		m.resizeNoteEditor()
+		if len(m.outline) > 0 {
+			m.jumpToHeading(m.outline[m.outlineCursor])
+		}
+	}
+	return m, nil
+}
+
+// jumpToHeading moves the preview viewport or the textarea cursor to h.
+func (m *model) jumpToHeading(h notes.Heading) {
+	if m.editingSource() {
+		moveEditorToLine(&m.noteEditor, h.Line)
+		return
+	}
+
+	// Headings may repeat, so count earlier headings with the same text.
+	occurrence := 0
+	for _, other := range m.outline {
+		if other.Line >= h.Line {
+			break
+		}
+		if other.Text == h.Text {
+			occurrence++
+		}
+	}
+	m.scrollPreviewTo(plainHeading(h.Text), occurrence)
+}
+
+// moveEditorToLine places the textarea cursor at the start of line.
+func moveEditorToLine(ta *textarea.Model, line int) {
+	line = max(0, min(line, ta.LineCount()-1))
+	// Each step moves one visual row, so soft-wrapped lines need several.
+	for guard := 0; ta.Line() > line && guard < 100000; guard++ {
+		ta.CursorUp()
+	}
+	for guard := 0; ta.Line() < line && guard < 100000; guard++ {
+		ta.CursorDown()
+	}
+	ta.CursorStart()
+}
+
+// scrollPreviewTo scrolls the preview to the occurrence-th rendered line
+// containing text. Glamour output does not map 1:1 to source lines, so the
+// rendered text is searched instead.
+func (m *model) scrollPreviewTo(text string, occurrence int) {
+	needle := strings.TrimSpace(text)
+	if runes := []rune(needle); len(runes) > 30 {
+		needle = string(runes[:30])
+	}
+	if needle == "" {
+		return
+	}
+	for i, line := range m.notePreviewLines {
+		if !strings.Contains(line, needle) {
+			continue
+		}
+		if occurrence > 0 {
+			occurrence--
+			continue
+		}
+		m.noteViewer.SetYOffset(max(0, i-2))
+		return
+	}
+}
+
+// plainHeading strips inline markdown emphasis so the heading can be found in
+// the rendered preview.
+func plainHeading(text string) string {
+	return strings.NewReplacer("**", "", "__", "", "`", "", "*", "", "~~", "").Replace(text)
+}
+
+// viewOutline renders the outline side panel.
+func (m model) viewOutline(height int) string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Outline") + "\n\n")
+	if len(m.outline) == 0 {
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("No headings"))
+	}
+
+	// Keep the cursor visible when there are more headings than rows.
+	rows := max(1, height-4)
+	start := 0
+	if m.outlineCursor >= rows {
+		start = m.outlineCursor - rows + 1
+	}
+	for i := start; i < len(m.outline) && i < start+rows; i++ {
+		h := m.outline[i]
+		line := strings.Repeat("  ", h.Level-1) + plainHeading(h.Text)
+		line = ansi.Truncate(line, outlineWidth-4, "…")
+		if i == m.outlineCursor {
+			b.WriteString(outlineSelectedStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	return outlineBoxStyle.Width(outlineWidth).Height(height - 2).Render(strings.TrimSuffix(b.String(), "\n"))
+}
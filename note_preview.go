@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"GoDash/widgets/notes"
+)
+
+var checklistStatusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#56b6c2"))
+
+// renderNotePreview renders m.noteContent into the preview viewport and
+// refreshes the checklist items and images of the open note.
+func (m *model) renderNotePreview() {
+	source, images := withImagePlaceholders(m.noteContent)
+	m.noteImages = images
+	if m.imageCursor >= len(images) {
+		m.imageCursor = max(0, len(images)-1)
+	}
+
+	rendered := m.noteContent
+	if m.markdownRenderer != nil {
+		if out, err := m.markdownRenderer.Render(source); err == nil {
+			rendered = out
+		}
+	}
+	m.noteViewer.SetContent(rendered)
+	m.notePreviewLines = strings.Split(ansi.Strip(rendered), "\n")
+
+	m.noteChecklist = notes.ParseChecklist(m.noteContent)
+	if m.checklistCursor >= len(m.noteChecklist) {
+		m.checklistCursor = max(0, len(m.noteChecklist)-1)
+	}
+}
+
+// moveChecklistCursor focuses the next (delta > 0) or previous checklist item
+// and scrolls the preview so the item is visible.
+func (m *model) moveChecklistCursor(delta int) {
+	if len(m.noteChecklist) == 0 {
+		return
+	}
+	m.checklistCursor = (m.checklistCursor + delta + len(m.noteChecklist)) % len(m.noteChecklist)
+
+	// Skip earlier items with the same text when locating the rendered line.
+	focused := m.noteChecklist[m.checklistCursor]
+	occurrence := 0
+	for _, item := range m.noteChecklist[:m.checklistCursor] {
+		if item.Text == focused.Text {
+			occurrence++
+		}
+	}
+	m.scrollPreviewTo(focused.Text, occurrence)
+}
+
+// toggleFocusedCheckbox flips the focused checklist item and writes the note.
+func (m *model) toggleFocusedCheckbox() error {
+	if len(m.noteChecklist) == 0 {
+		return nil
+	}
+	focused := m.noteChecklist[m.checklistCursor]
+	content, ok := notes.ToggleChecklistLine(m.noteContent, focused.Line)
+	if !ok {
+		return nil
+	}
+	if err := m.writeNote(content); err != nil {
+		return fmt.Errorf("could not save note: %w", err)
+	}
+
+	m.noteContent = content
+	m.originalContent = content
+	m.noteEditor.SetValue(content)
+	m.editHistory.reset()
+	m.hasUnsavedChanges = false
+	m.discardDraft()
+	m.notes = m.notes.Reload()
+	m.renderNotePreview()
+
+	m.saveMessage = "✅ Checklist updated!"
+	m.saveMessageTimer = 3
+	return nil
+}
+
+// checklistStatus describes the focused checklist item in preview mode.
+func (m model) checklistStatus() string {
+	if len(m.noteChecklist) == 0 {
+		return ""
+	}
+	focused := m.noteChecklist[m.checklistCursor]
+	box := "☐"
+	if focused.Done {
+		box = "☑"
+	}
+	status := fmt.Sprintf("%s %s (%d/%d)", box, focused.Text, m.checklistCursor+1, len(m.noteChecklist))
+	hint := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  x toggle · [ ] move")
+	maxWidth := m.noteViewer.Width - lipgloss.Width(hint)
+	if maxWidth > 0 && lipgloss.Width(status) > maxWidth {
+		status = ansi.Truncate(status, maxWidth, "…")
+	}
+	return checklistStatusStyle.Render(status) + hint
+}
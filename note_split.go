@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// splitPreviewDelay debounces re-rendering the live preview while typing.
+const splitPreviewDelay = 300 * time.Millisecond
+
+// splitPreviewMsg asks for the live preview to be re-rendered. Messages for
+// outdated versions are dropped so only the last keystroke renders.
+type splitPreviewMsg struct{ version int }
+
+// editingSource reports whether the textarea is active, which is the case in
+// both source and split mode.
+func (m model) editingSource() bool {
+	return m.noteEditorMode == noteSourceMode || m.noteEditorMode == noteSplitMode
+}
+
+// splitPaneWidth returns the width of each pane in split mode.
+func (m model) splitPaneWidth() int {
+	contentWidth, _ := m.noteContentSize()
+	return max(10, (contentWidth-3)/2) // 3 columns for the separator
+}
+
+// toggleSplitMode switches between split mode and plain source mode.
+func (m *model) toggleSplitMode() {
+	if m.noteEditorMode == noteSplitMode {
+		m.noteEditorMode = noteSourceMode
+		m.resizeNoteEditor()
+		return
+	}
+	m.noteEditorMode = noteSplitMode
+	m.resizeNoteEditor()
+	m.noteEditor.Focus()
+	m.renderSplitPreview()
+}
+
+// scheduleSplitPreview debounces a re-render of the live preview.
+func (m *model) scheduleSplitPreview() tea.Cmd {
+	m.splitPreviewVersion++
+	version := m.splitPreviewVersion
+	return tea.Tick(splitPreviewDelay, func(time.Time) tea.Msg {
+		return splitPreviewMsg{version: version}
+	})
+}
+
+// renderSplitPreview renders the textarea contents into the preview pane,
+// wrapping at the pane width rather than the full preview width.
+func (m *model) renderSplitPreview() {
+	width := m.splitPaneWidth()
+	if m.splitRenderer == nil || m.splitRendererWidth != width {
+		renderer, err := glamour.NewTermRenderer(
+			glamour.WithStandardStyle(m.markdownStyle),
+			glamour.WithWordWrap(width-2),
+		)
+		if err == nil {
+			m.splitRenderer = renderer
+			m.splitRendererWidth = width
+		}
+	}
+
+	content := m.noteEditor.Value()
+	source, _ := withImagePlaceholders(content)
+	rendered := content
+	if m.splitRenderer != nil {
+		if out, err := m.splitRenderer.Render(source); err == nil {
+			rendered = out
+		}
+	}
+	m.noteViewer.SetContent(rendered)
+	m.notePreviewLines = strings.Split(ansi.Strip(rendered), "\n")
+	m.syncSplitScroll()
+}
+
+// syncSplitScroll scrolls the preview pane to the same relative position as
+// the textarea cursor.
+func (m *model) syncSplitScroll() {
+	lines := m.noteEditor.LineCount()
+	scrollable := m.noteViewer.TotalLineCount() - m.noteViewer.Height
+	if lines <= 1 || scrollable <= 0 {
+		m.noteViewer.GotoTop()
+		return
+	}
+	m.noteViewer.SetYOffset(m.noteEditor.Line() * scrollable / (lines - 1))
+}
+
+// viewSplit renders the textarea and the live preview side by side. The panes
+// are sized by resizeNoteEditor.
+func (m model) viewSplit(height int) string {
+	separator := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#4b5263")).
+		Padding(0, 1).
+		Render(strings.TrimSuffix(strings.Repeat("│\n", height), "\n"))
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, m.noteEditor.View(), separator, m.noteViewer.View())
+}
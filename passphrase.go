@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"GoDash/widgets/notes"
+)
+
+// passphrasePurpose tells the passphrase prompt what to do once a passphrase
+// has been entered.
+type passphrasePurpose int
+
+const (
+	passphraseOpen    passphrasePurpose = iota // unlock an encrypted note for editing
+	passphraseEncrypt                          // encrypt a plaintext note
+	passphraseDecrypt                          // permanently remove a note's encryption
+)
+
+// passphrasePrompt holds the state of statePassphrasePrompt.
+type passphrasePrompt struct {
+	input      textinput.Model
+	purpose    passphrasePurpose
+	path       string
+	ciphertext []byte
+	first      string // first entry while confirming a new passphrase
+	errMsg     string
+}
+
+// startPassphrasePrompt switches to the passphrase prompt for the note at path.
+func (m *model) startPassphrasePrompt(purpose passphrasePurpose, path string, ciphertext []byte) tea.Cmd {
+	ti := textinput.New()
+	ti.EchoMode = textinput.EchoPassword
+	ti.EchoCharacter = '•'
+	ti.CharLimit = 256
+	ti.Width = 40
+	ti.Placeholder = "Passphrase"
+	ti.Focus()
+
+	m.passphrase = passphrasePrompt{
+		input:      ti,
+		purpose:    purpose,
+		path:       path,
+		ciphertext: ciphertext,
+	}
+	m.state = statePassphrasePrompt
+	m.updateKeybindings()
+	return textinput.Blink
+}
+
+// closePassphrasePrompt returns to the dashboard and drops any entered secret.
+func (m *model) closePassphrasePrompt() {
+	m.passphrase = passphrasePrompt{}
+	m.state = stateDashboard
+	m.updateKeybindings()
+}
+
+// --- UPDATE: PASSPHRASE PROMPT ---
+func (m model) updatePassphrasePrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+			m.closePassphrasePrompt()
+			return m, nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+			value := m.passphrase.input.Value()
+			if value == "" {
+				return m, nil
+			}
+			m.passphrase.input.Reset()
+			return m.submitPassphrase(value)
+		}
+	}
+
+	m.passphrase.input, cmd = m.passphrase.input.Update(msg)
+	return m, cmd
+}
+
+// submitPassphrase acts on a passphrase entered for the current purpose.
+func (m model) submitPassphrase(value string) (tea.Model, tea.Cmd) {
+	p := &m.passphrase
+
+	switch p.purpose {
+	case passphraseOpen:
+		plaintext, err := notes.DecryptNote(p.ciphertext, value)
+		if err != nil {
+			p.errMsg = passphraseError(err)
+			return m, nil
+		}
+		path := p.path
+		m.passphrase = passphrasePrompt{}
+		m.notePassphrase = value
+		m.openNoteEditor(path, string(plaintext))
+		return m, nil
+
+	case passphraseEncrypt:
+		if p.first == "" {
+			p.first = value
+			p.errMsg = ""
+			return m, nil
+		}
+		if p.first != value {
+			p.first = ""
+			p.errMsg = "Passphrases do not match, try again"
+			return m, nil
+		}
+		if _, err := notes.EncryptNoteFile(p.path, value); err != nil {
+			p.errMsg = passphraseError(err)
+			return m, nil
+		}
+
+	case passphraseDecrypt:
+		if _, err := notes.DecryptNoteFile(p.path, value); err != nil {
+			p.errMsg = passphraseError(err)
+			return m, nil
+		}
+	}
+
+	m.notes = m.notes.Reload()
+	m.closePassphrasePrompt()
+	return m, nil
+}
+
+func passphraseError(err error) string {
+	if errors.Is(err, notes.ErrWrongPassphrase) {
+		return "Wrong passphrase"
+	}
+	return err.Error()
+}
+
+// --- VIEW: PASSPHRASE PROMPT ---
+func (m model) viewPassphrasePrompt() string {
+	var title, message string
+	switch m.passphrase.purpose {
+	case passphraseOpen:
+		title = "🔒 Encrypted Note"
+		message = "Enter the passphrase to open this note."
+	case passphraseEncrypt:
+		title = "🔒 Encrypt Note"
+		message = "Choose a passphrase. It cannot be recovered if lost."
+		if m.passphrase.first != "" {
+			message = "Enter the passphrase again to confirm."
+		}
+	case passphraseDecrypt:
+		title = "🔓 Remove Encryption"
+		message = "Enter the passphrase to store this note as plain text."
+	}
+
+	parts := []string{
+		helpTitleStyle.Render(title),
+		"",
+		message,
+		"",
+		m.passphrase.input.View(),
+	}
+	if m.passphrase.errMsg != "" {
+		parts = append(parts, "", redText.Render(m.passphrase.errMsg))
+	}
+	instructions := "Enter to confirm, Esc to cancel"
+	parts = append(parts, "", lipgloss.NewStyle().Foreground(lipgloss.Color("#7c7c7c")).Render(instructions))
+
+	content := lipgloss.JoinVertical(lipgloss.Center, parts...)
+	dialogBox := helpBoxStyle.Width(60).Align(lipgloss.Center).Render(content)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, dialogBox)
+}
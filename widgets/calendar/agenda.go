@@ -0,0 +1,104 @@
+package calendar
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// agendaTimeWidth is the width of the time column, e.g. "09:00–10:30".
+const agendaTimeWidth = 11
+
+var (
+	agendaTimeStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#abb2bf"))
+	agendaSummaryStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#be8a59"))
+	agendaPastStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#5c6370"))
+	agendaNowStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#98c379")).Bold(true)
+	agendaSelectedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#56b6c2")).Bold(true)
+	agendaBadgeStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#282c34"))
+)
+
+// eventColor returns the color of the event or, if it has none, of its
+// calendar.
+func (m *Model) eventColor(e Event) string {
+	if e.Color != "" {
+		return e.Color
+	}
+	if color, ok := m.colors[e.Calendar]; ok {
+		return color
+	}
+	return calendarPalette[0]
+}
+
+// agendaLine renders an event as shown on day, fitting width:
+//
+//	>▌09:00–10:30 Standup
+//	●▌14:00–15:00 Review
+//	 ▌ all day    Conference
+//
+// The first column marks the selected event, or with a green dot the timed
+// event in progress. Times outside day are shown as "…".
+func (m *Model) agendaLine(e Event, day time.Time, width int, selected bool, now time.Time) string {
+	inProgress := !e.AllDay && !e.Start.After(now) && e.End.After(now)
+	past := !e.End.After(now)
+
+	marker := " "
+	switch {
+	case selected:
+		marker = agendaSelectedStyle.Render(">")
+	case inProgress:
+		marker = agendaNowStyle.Render("●")
+	}
+	color := lipgloss.Color(m.eventColor(e))
+	bar := lipgloss.NewStyle().Foreground(color).Render("▌")
+
+	var when string
+	if e.AllDay {
+		when = agendaBadgeStyle.Background(color).Render(" all day ") + "  "
+	} else {
+		when = agendaTime(e, day)
+		switch {
+		case inProgress:
+			when = agendaNowStyle.Render(when)
+		case past:
+			when = agendaPastStyle.Render(when)
+		default:
+			when = agendaTimeStyle.Render(when)
+		}
+	}
+
+	summaryStyle := agendaSummaryStyle
+	switch {
+	case selected:
+		summaryStyle = agendaSelectedStyle
+	case past:
+		summaryStyle = agendaPastStyle
+	}
+	room := max(0, width-2-agendaTimeWidth-1)
+	summary := ansi.Truncate(strings.ReplaceAll(e.Summary, "\n", " "), room, "…")
+
+	return marker + bar + when + " " + summaryStyle.Render(summary)
+}
+
+// agendaTime formats the start and end of a timed event in local time,
+// padded to agendaTimeWidth.
+func agendaTime(e Event, day time.Time) string {
+	start, end := e.Start.Local(), e.End.Local()
+	from, to := start.Format("15:04"), end.Format("15:04")
+	if start.Before(dayStart(day)) {
+		from = "…"
+	}
+	if !end.Before(dayStart(day).AddDate(0, 0, 1)) {
+		to = "…"
+	}
+	if !end.After(start) {
+		to = ""
+	}
+	s := from + "–" + to
+	if to == "" {
+		s = from
+	}
+	return s + strings.Repeat(" ", max(0, agendaTimeWidth-ansi.StringWidth(s)))
+}
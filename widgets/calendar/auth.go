@@ -0,0 +1,503 @@
+package calendar
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/calendar/v3"
+
+	"GoDash/internal/config"
+)
+
+//go:embed 1761.png
+var logoImage []byte
+
+// credentialsEnv names a variable pointing to the OAuth client credentials,
+// for packagers who ship them outside the config directory.
+const credentialsEnv = "GODASH_CREDENTIALS"
+
+// authTimeout is how long an authorization may take.
+const authTimeout = 5 * time.Minute
+
+// manualRedirectURL is the redirect of the manual flow. Nothing listens
+// there, so the browser shows an error page whose address holds the code.
+const manualRedirectURL = "http://127.0.0.1/callback"
+
+// ErrAuthRestarted is the result of a flow replaced by a newer one or
+// canceled.
+var ErrAuthRestarted = errors.New("authorization restarted")
+
+// ErrNoCredentials is returned when the OAuth client credentials are missing.
+var ErrNoCredentials = errors.New("no Google OAuth client credentials")
+
+// authFlow is a running authorization of a Google account. The state and
+// PKCE verifier are new for every flow.
+type authFlow struct {
+	account  string
+	config   *oauth2.Config
+	state    string
+	verifier string
+	server   *http.Server // nil for the manual and device flows
+	device   *DeviceCode  // set for the device flow
+	cancel   context.CancelFunc
+	done     chan struct{} // closed once the flow finished
+	err      error         // result of the flow, set before done is closed
+	once     sync.Once
+}
+
+var (
+	flowMu      sync.Mutex
+	currentFlow *authFlow
+)
+
+// StartAuthFlow starts authorizing account and returns the URL to open in
+// the browser. It listens for the redirect on a free loopback port and falls
+// back to the manual flow, where the user pastes the address the browser
+// was sent to, if it can't.
+func StartAuthFlow(account string) (string, error) {
+	cfg, err := getConfig()
+	if err != nil {
+		return "", err
+	}
+	state, err := randomState()
+	if err != nil {
+		return "", err
+	}
+	flow := &authFlow{
+		account:  account,
+		config:   cfg,
+		state:    state,
+		verifier: oauth2.GenerateVerifier(),
+		done:     make(chan struct{}),
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err == nil {
+		port := listener.Addr().(*net.TCPAddr).Port
+		cfg.RedirectURL = fmt.Sprintf("http://127.0.0.1:%d/callback", port)
+		mux := http.NewServeMux()
+		mux.HandleFunc("/callback", flow.handleCallback)
+		flow.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go flow.server.Serve(listener)
+		time.AfterFunc(authTimeout, func() { flow.finish(fmt.Errorf("authentication timeout")) })
+	} else {
+		cfg.RedirectURL = manualRedirectURL
+	}
+
+	flowMu.Lock()
+	if currentFlow != nil {
+		currentFlow.finish(ErrAuthRestarted)
+	}
+	currentFlow = flow
+	flowMu.Unlock()
+
+	return cfg.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(flow.verifier)), nil
+}
+
+// CancelAuth stops the running authorization, if any.
+func CancelAuth() {
+	flowMu.Lock()
+	defer flowMu.Unlock()
+	if currentFlow != nil {
+		currentFlow.finish(ErrAuthRestarted)
+		currentFlow = nil
+	}
+}
+
+// GetAuthURL returns the URL the user needs to visit to authorize the application.
+// This is kept for backward compatibility but now uses the new flow.
+func GetAuthURL(account string) (string, error) {
+	return StartAuthFlow(account)
+}
+
+// IsUsingManualFlow returns true if we're using manual code flow
+func IsUsingManualFlow() bool {
+	flowMu.Lock()
+	defer flowMu.Unlock()
+	return currentFlow != nil && currentFlow.server == nil && currentFlow.device == nil
+}
+
+// DeviceCode is what the user needs to authorize GoDash from another device.
+type DeviceCode struct {
+	UserCode        string
+	VerificationURL string
+	Expires         time.Time
+}
+
+// StartDeviceAuth starts authorizing account with the device flow, for
+// sessions without a browser such as SSH. The user opens VerificationURL on
+// any device and enters UserCode, while GoDash polls Google in the
+// background until the flow is approved, denied or expires. WaitForAuth
+// returns the result.
+func StartDeviceAuth(account string) (DeviceCode, error) {
+	cfg, err := getDeviceConfig()
+	if err != nil {
+		return DeviceCode{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	resp, err := cfg.DeviceAuth(ctx)
+	cancel()
+	if err != nil {
+		return DeviceCode{}, fmt.Errorf("unable to start device authorization: %v", err)
+	}
+
+	code := DeviceCode{UserCode: resp.UserCode, VerificationURL: resp.VerificationURI, Expires: resp.Expiry}
+	if resp.VerificationURIComplete != "" {
+		code.VerificationURL = resp.VerificationURIComplete
+	}
+	if code.Expires.IsZero() {
+		code.Expires = time.Now().Add(authTimeout)
+	}
+	flow := &authFlow{
+		account: account,
+		config:  cfg,
+		device:  &code,
+		done:    make(chan struct{}),
+	}
+	ctx, flow.cancel = context.WithDeadline(context.Background(), code.Expires)
+
+	flowMu.Lock()
+	if currentFlow != nil {
+		currentFlow.finish(ErrAuthRestarted)
+	}
+	currentFlow = flow
+	flowMu.Unlock()
+
+	go func() {
+		tok, err := cfg.DeviceAccessToken(ctx, resp)
+		if err == nil {
+			err = saveToken(account, clientDevice, tok)
+		} else if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("the code expired, start again")
+		} else {
+			err = fmt.Errorf("device authorization failed: %v", err)
+		}
+		flow.finish(err)
+	}()
+	return code, nil
+}
+
+// DeviceQRCode renders url as a QR code of half blocks, so the verification
+// page can be opened by scanning the terminal with a phone.
+func DeviceQRCode(url string) (string, error) {
+	qr, err := qrcode.New(url, qrcode.Low)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(qr.ToSmallString(false), "\n"), nil
+}
+
+// CompleteAuth finishes the manual flow for account. input is the address
+// the browser was redirected to, whose state is checked, or just the code.
+func CompleteAuth(account, input string) error {
+	flowMu.Lock()
+	flow := currentFlow
+	flowMu.Unlock()
+	if flow == nil || flow.account != account {
+		return fmt.Errorf("no authorization is running for this account, start it again")
+	}
+
+	code := strings.TrimSpace(input)
+	if u, err := url.Parse(code); err == nil && u.Query().Has("code") {
+		if !flow.validState(u.Query().Get("state")) {
+			return fmt.Errorf("the address doesn't belong to this authorization, start it again")
+		}
+		code = u.Query().Get("code")
+	}
+	if err := flow.exchange(context.TODO(), code); err != nil {
+		return err
+	}
+	flow.finish(nil)
+	return nil
+}
+
+// WaitForAuth waits for the OAuth flow to complete and returns any error
+func WaitForAuth() error {
+	flowMu.Lock()
+	flow := currentFlow
+	flowMu.Unlock()
+	if flow == nil {
+		return fmt.Errorf("no authorization is running")
+	}
+	<-flow.done
+	return flow.err
+}
+
+// revokeURL is Google's endpoint for revoking tokens.
+const revokeURL = "https://oauth2.googleapis.com/revoke"
+
+// SignOut revokes GoDash's access to account and deletes its token. The
+// token is deleted even if Google can't be reached, in which case access
+// can still be removed from the Google account settings.
+func SignOut(ctx context.Context, account string) error {
+	tok, err := loadToken(account)
+	if errors.Is(err, ErrAuthRequired) {
+		return err
+	}
+
+	var revokeErr error
+	if err == nil {
+		revokeErr = revokeToken(ctx, tok.Token)
+	}
+	if err := deleteToken(account); err != nil {
+		return err
+	}
+	if revokeErr != nil {
+		return fmt.Errorf("the token was deleted, but Google could not revoke it: %v", revokeErr)
+	}
+	return nil
+}
+
+// revokeToken asks Google to revoke tok. Revoking the refresh token also
+// revokes the access tokens issued with it.
+func revokeToken(ctx context.Context, tok *oauth2.Token) error {
+	token := tok.RefreshToken
+	if token == "" {
+		token = tok.AccessToken
+	}
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, revokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Tokens that expired or were revoked already are refused as invalid.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("unexpected response %s", resp.Status)
+	}
+	return nil
+}
+
+// randomState returns an unguessable OAuth state.
+func randomState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("unable to generate state: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (f *authFlow) validState(state string) bool {
+	return subtle.ConstantTimeCompare([]byte(state), []byte(f.state)) == 1
+}
+
+// exchange trades code for a token and saves it as the account's token.
+func (f *authFlow) exchange(ctx context.Context, code string) error {
+	tok, err := f.config.Exchange(ctx, code, oauth2.VerifierOption(f.verifier))
+	if err != nil {
+		return fmt.Errorf("unable to retrieve token from web: %v", err)
+	}
+	return saveToken(f.account, "", tok)
+}
+
+// finish reports the result and stops the callback server, once.
+func (f *authFlow) finish(err error) {
+	f.once.Do(func() {
+		f.err = err
+		close(f.done)
+		if f.cancel != nil {
+			f.cancel()
+		}
+		if f.server != nil {
+			go f.server.Shutdown(context.Background())
+		}
+	})
+}
+
+// handleCallback receives the redirect from Google. Requests without the
+// flow's state are rejected, so other pages can't inject a code.
+func (f *authFlow) handleCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if !f.validState(query.Get("state")) {
+		http.Error(w, "Invalid state", http.StatusBadRequest)
+		return
+	}
+	if errorMsg := query.Get("error"); errorMsg != "" {
+		http.Error(w, fmt.Sprintf("OAuth error: %s", errorMsg), http.StatusBadRequest)
+		f.finish(fmt.Errorf("oauth error: %s", errorMsg))
+		return
+	}
+	code := query.Get("code")
+	if code == "" {
+		http.Error(w, "No authorization code received", http.StatusBadRequest)
+		f.finish(fmt.Errorf("no authorization code received"))
+		return
+	}
+
+	if err := f.exchange(r.Context(), code); err != nil {
+		http.Error(w, "Token exchange failed", http.StatusInternalServerError)
+		f.finish(err)
+		return
+	}
+
+	writeSuccessPage(w)
+	f.finish(nil)
+}
+
+// writeSuccessPage tells the user the browser can be closed.
+func writeSuccessPage(w http.ResponseWriter) {
+	// Encode the logo as base64 for embedding
+	logoBase64 := base64.StdEncoding.EncodeToString(logoImage)
+
+	// Success page with One Dark theme
+	html := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <title>GoDash - Authentication Successful</title>
+    <style>
+        body { 
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', 'JetBrains Mono', monospace, Roboto, sans-serif; 
+            text-align: center; 
+            padding: 50px; 
+            background: #282c34;
+            color: #abb2bf;
+            margin: 0;
+            min-height: 100vh;
+            display: flex;
+            align-items: center;
+            justify-content: center;
+        }
+        .container { 
+            background: #21252b; 
+            padding: 50px 40px; 
+            border-radius: 12px; 
+            box-shadow: 0 10px 30px rgba(0,0,0,0.4);
+            max-width: 500px;
+            border: 1px solid #3e4451;
+            animation: slideUp 0.5s ease-out;
+        }
+        @keyframes slideUp {
+            from { opacity: 0; transform: translateY(30px); }
+            to { opacity: 1; transform: translateY(0); }
+        }
+        .title { 
+            color: #98c379; 
+            font-size: 32px; 
+            margin-bottom: 30px;
+            font-weight: 600;
+        }
+        .logo {
+            margin: 20px 0;
+        }
+        .logo img {
+            max-width: 200px;
+            height: auto;
+        }
+        .message { 
+            color: #abb2bf; 
+            font-size: 18px;
+            line-height: 1.6;
+            margin-bottom: 20px;
+        }
+        .app-name { 
+            color: #61afef; 
+            font-weight: 600; 
+        }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="title">Authentication Successful!</div>
+        <div class="logo">
+            <img src="data:image/png;base64,%s" alt="GoDash Logo" />
+        </div>
+        <div class="message">
+            You can now close this browser window.<br><br>
+            <span class="app-name">GoDash Application</span> has been authorized to access your Google Calendar.
+        </div>
+    </div>
+</body>
+</html>
+`, logoBase64)
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(html))
+}
+
+// credentialsPath returns where the OAuth client credentials are read from:
+// the file named by GODASH_CREDENTIALS, or credentials.json in the config
+// directory.
+func credentialsPath() (string, error) {
+	if path := os.Getenv(credentialsEnv); path != "" {
+		return path, nil
+	}
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "credentials.json"), nil
+}
+
+// CheckCredentials reports whether an OAuth client can be loaded, either
+// for the device flow or the usual one, returning an error wrapping
+// ErrNoCredentials if there is none. Without one GoDash can neither
+// authorize nor refresh Google tokens.
+func CheckCredentials() error {
+	_, err := getDeviceConfig()
+	return err
+}
+
+// getDeviceConfig loads the OAuth2 config of the device flow. Google only
+// offers it to clients of the "TVs and Limited Input devices" type, which
+// are read from device-credentials.json in the config directory when
+// present; otherwise the usual credentials are tried.
+func getDeviceConfig() (*oauth2.Config, error) {
+	var cfg *oauth2.Config
+	configDir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(configDir, "device-credentials.json")
+	if data, err := os.ReadFile(path); err == nil {
+		cfg, err = google.ConfigFromJSON(data, calendar.CalendarEventsScope, calendar.CalendarCalendarlistReadonlyScope)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credentials in %s: %v", path, err)
+		}
+	} else if cfg, err = getConfig(); err != nil {
+		return nil, err
+	}
+	cfg.Endpoint.DeviceAuthURL = google.Endpoint.DeviceAuthURL
+	return cfg, nil
+}
+
+// getConfig loads the OAuth2 config from the client credentials file.
+func getConfig() (*oauth2.Config, error) {
+	path, err := credentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: save the OAuth client of a Google Cloud desktop app as %s", ErrNoCredentials, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read credentials: %v", err)
+	}
+	cfg, err := google.ConfigFromJSON(data, calendar.CalendarEventsScope, calendar.CalendarCalendarlistReadonlyScope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credentials in %s: %v", path, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,347 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"GoDash/internal/config"
+	"GoDash/internal/secrets"
+)
+
+// calDAVProvider reads and edits a calendar collection on a CalDAV server
+// such as Nextcloud, Radicale or Fastmail.
+type calDAVProvider struct {
+	name     string
+	url      string
+	username string
+	password string
+	client   *http.Client
+}
+
+func newCalDAVProvider(src config.CalendarSource) (*calDAVProvider, error) {
+	u, err := url.Parse(src.URL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("caldav calendar %q needs the URL of a calendar collection", src.Name)
+	}
+	name := src.Name
+	if name == "" {
+		name = u.Host
+	}
+	password, err := calDAVPassword(src)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the password of caldav calendar %q: %v", name, err)
+	}
+	return &calDAVProvider{
+		name:     name,
+		url:      src.URL,
+		username: src.Username,
+		password: password,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// calDAVPasswordKey names the secret holding the password of a CalDAV
+// calendar.
+func calDAVPasswordKey(src config.CalendarSource) string {
+	return "caldav-password:" + src.Username + "@" + src.URL
+}
+
+// calDAVPassword returns the password of a CalDAV calendar: the one written
+// in config.json if it wasn't moved yet, or the one in the secret store.
+func calDAVPassword(src config.CalendarSource) (string, error) {
+	if src.Password != "" {
+		return src.Password, nil
+	}
+	data, err := secrets.Default().Get(calDAVPasswordKey(src))
+	if errors.Is(err, secrets.ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// MigrateCalDAVPasswords moves the CalDAV passwords written in config.json
+// into the secret store, removing them from settings and saving it. A
+// password the store refuses is left in place.
+func MigrateCalDAVPasswords(settings *config.Settings) error {
+	var errs []error
+	moved := false
+	for i, src := range settings.Calendars {
+		if src.Type != "caldav" || src.Password == "" {
+			continue
+		}
+		if err := secrets.Default().Set(calDAVPasswordKey(src), []byte(src.Password)); err != nil {
+			errs = append(errs, fmt.Errorf("unable to store the password of caldav calendar %q: %v", src.Name, err))
+			continue
+		}
+		settings.Calendars[i].Password = ""
+		moved = true
+	}
+	if moved {
+		if err := config.SaveSettings(*settings); err != nil {
+			errs = append(errs, fmt.Errorf("unable to remove the caldav passwords from config.json: %v", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *calDAVProvider) Name() string { return p.name }
+
+// calendarQuery asks for the events in a time range. The server expands
+// recurring events into their instances.
+const calendarQuery = `<?xml version="1.0" encoding="utf-8" ?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop>
+    <D:getetag/>
+    <C:calendar-data>
+      <C:expand start="%[1]s" end="%[2]s"/>
+    </C:calendar-data>
+  </D:prop>
+  <C:filter>
+    <C:comp-filter name="VCALENDAR">
+      <C:comp-filter name="VEVENT">
+        <C:time-range start="%[1]s" end="%[2]s"/>
+      </C:comp-filter>
+    </C:comp-filter>
+  </C:filter>
+</C:calendar-query>`
+
+// multistatus is the WebDAV response to a REPORT request.
+type multistatus struct {
+	Responses []struct {
+		Href      string `xml:"href"`
+		Propstats []struct {
+			Status string `xml:"status"`
+			Prop   struct {
+				ETag         string `xml:"getetag"`
+				CalendarData string `xml:"calendar-data"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+func (p *calDAVProvider) Events(ctx context.Context, start, end time.Time) ([]Event, error) {
+	const layout = "20060102T150405Z"
+	body := fmt.Sprintf(calendarQuery, start.UTC().Format(layout), end.UTC().Format(layout))
+
+	req, err := http.NewRequestWithContext(ctx, "REPORT", p.url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	req.Header.Set("Depth", "1")
+	if p.username != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reach CalDAV server: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, fmt.Errorf("CalDAV server returned %s", resp.Status)
+	}
+
+	var ms multistatus
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 64<<20)).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("invalid CalDAV response: %v", err)
+	}
+
+	var events []Event
+	for _, r := range ms.Responses {
+		for _, ps := range r.Propstats {
+			if ps.Prop.CalendarData == "" || (ps.Status != "" && !strings.Contains(ps.Status, " 200 ")) {
+				continue
+			}
+			// Recurrences are expanded again in case the server ignored
+			// the expand request.
+			parsed, err := eventsFromICSData(strings.NewReader(ps.Prop.CalendarData), start, end)
+			if err != nil {
+				continue // skip malformed objects rather than the whole calendar
+			}
+			for i := range parsed {
+				parsed[i].Href = r.Href
+				for j, a := range parsed[i].Attendees {
+					parsed[i].Attendees[j].Self = p.username != "" && strings.EqualFold(a.Email, p.username)
+				}
+			}
+			events = append(events, parsed...)
+		}
+	}
+	return events, nil
+}
+
+// errRecurringCalDAV is returned when editing an occurrence of a recurring
+// event, which would need changes to the whole series.
+var errRecurringCalDAV = errors.New("recurring events on CalDAV calendars can't be changed from GoDash")
+
+func (p *calDAVProvider) CreateEvent(ctx context.Context, e Event) (Event, error) {
+	e.ID = newUID()
+	e.Href = p.objectURL(e.ID + ".ics")
+
+	var body bytes.Buffer
+	if err := writeICS(&body, newICSEvent(e)); err != nil {
+		return Event{}, err
+	}
+	// If-None-Match keeps us from overwriting an existing object.
+	if err := p.put(ctx, e.Href, body.Bytes(), "If-None-Match", "*"); err != nil {
+		return Event{}, err
+	}
+	e.Recurring = false
+	return e, nil
+}
+
+// UpdateEvent downloads the event's object, changes the edited properties
+// and uploads it again, so alarms, attendees and properties set by other
+// clients are kept.
+func (p *calDAVProvider) UpdateEvent(ctx context.Context, e Event) (Event, error) {
+	if e.Recurring {
+		return Event{}, errRecurringCalDAV
+	}
+	root, etag, err := p.get(ctx, e.Href)
+	if err != nil {
+		return Event{}, err
+	}
+	var vevent *icsComponent
+	for _, comp := range root.Components {
+		if comp.Name != "VEVENT" {
+			continue
+		}
+		if vevent != nil {
+			return Event{}, errRecurringCalDAV
+		}
+		vevent = comp
+	}
+	if vevent == nil {
+		return Event{}, fmt.Errorf("%s doesn't contain an event", e.Href)
+	}
+	for _, name := range []string{"RRULE", "RDATE", "RECURRENCE-ID"} {
+		if _, ok := vevent.prop(name); ok {
+			return Event{}, errRecurringCalDAV
+		}
+	}
+	vevent.setEvent(e)
+
+	var body bytes.Buffer
+	if err := writeICS(&body, root); err != nil {
+		return Event{}, err
+	}
+	var header []string
+	if etag != "" {
+		// Fail instead of overwriting changes made since the download.
+		header = []string{"If-Match", etag}
+	}
+	if err := p.put(ctx, e.Href, body.Bytes(), header...); err != nil {
+		return Event{}, err
+	}
+	return e, nil
+}
+
+func (p *calDAVProvider) DeleteEvent(ctx context.Context, e Event) error {
+	if e.Recurring {
+		return errRecurringCalDAV
+	}
+	req, err := p.request(ctx, http.MethodDelete, e.Href, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to reach CalDAV server: %v", err)
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+	return fmt.Errorf("CalDAV server returned %s", resp.Status)
+}
+
+// get downloads a calendar object and returns its VCALENDAR and ETag.
+func (p *calDAVProvider) get(ctx context.Context, href string) (*icsComponent, string, error) {
+	req, err := p.request(ctx, http.MethodGet, href, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to reach CalDAV server: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("CalDAV server returned %s", resp.Status)
+	}
+	roots, err := parseICS(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid calendar object %s: %v", href, err)
+	}
+	for _, root := range roots {
+		if root.Name == "VCALENDAR" {
+			return root, resp.Header.Get("ETag"), nil
+		}
+	}
+	return nil, "", fmt.Errorf("invalid calendar object %s", href)
+}
+
+// put uploads a calendar object. header holds extra header name/value pairs.
+func (p *calDAVProvider) put(ctx context.Context, href string, body []byte, header ...string) error {
+	req, err := p.request(ctx, http.MethodPut, href, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/calendar; charset=utf-8")
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to reach CalDAV server: %v", err)
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	case http.StatusPreconditionFailed:
+		return errors.New("the event was changed on the server, refresh and try again")
+	}
+	return fmt.Errorf("CalDAV server returned %s", resp.Status)
+}
+
+func (p *calDAVProvider) request(ctx context.Context, method, href string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.objectURL(href), body)
+	if err != nil {
+		return nil, err
+	}
+	if p.username != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+	return req, nil
+}
+
+// objectURL resolves href, which servers usually report as an absolute
+// path, against the collection URL.
+func (p *calDAVProvider) objectURL(href string) string {
+	base, err := url.Parse(p.url)
+	if err != nil {
+		return href
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	ref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return base.ResolveReference(ref).String()
+}
@@ -0,0 +1,306 @@
+package calendar
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"GoDash/internal/config"
+)
+
+// fakeCalDAV is an in-memory calendar collection at /cal/.
+type fakeCalDAV struct {
+	mu      sync.Mutex
+	objects map[string]string // by path
+	etags   map[string]int
+	// editAfterGet simulates another client changing an object right after
+	// it was downloaded.
+	editAfterGet bool
+}
+
+func newFakeCalDAV(objects map[string]string) *fakeCalDAV {
+	f := &fakeCalDAV{objects: make(map[string]string), etags: make(map[string]int)}
+	for path, data := range objects {
+		f.objects[path] = data
+		f.etags[path] = 1
+	}
+	return f
+}
+
+func (f *fakeCalDAV) etag(path string) string {
+	return fmt.Sprintf(`"%d"`, f.etags[path])
+}
+
+func (f *fakeCalDAV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if user, pass, ok := r.BasicAuth(); !ok || user != "me@example.com" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := r.URL.Path
+	data, exists := f.objects[path]
+	switch r.Method {
+	case "REPORT":
+		if path != "/cal/" || r.Header.Get("Depth") != "1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var b strings.Builder
+		b.WriteString(`<?xml version="1.0" encoding="utf-8"?>` +
+			`<D:multistatus xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">`)
+		for path, data := range f.objects {
+			b.WriteString("<D:response><D:href>" + path + "</D:href><D:propstat><D:prop>")
+			b.WriteString("<D:getetag>" + f.etag(path) + "</D:getetag><C:calendar-data>")
+			xml.EscapeText(&b, []byte(data))
+			b.WriteString("</C:calendar-data></D:prop><D:status>HTTP/1.1 200 OK</D:status></D:propstat></D:response>")
+		}
+		// Objects the server can't return are reported with an error status.
+		b.WriteString("<D:response><D:href>/cal/broken.ics</D:href><D:propstat><D:prop>" +
+			"<C:calendar-data>BEGIN:VCALENDAR</C:calendar-data></D:prop>" +
+			"<D:status>HTTP/1.1 404 Not Found</D:status></D:propstat></D:response>")
+		b.WriteString("</D:multistatus>")
+		w.WriteHeader(http.StatusMultiStatus)
+		io.WriteString(w, b.String())
+	case http.MethodGet:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", f.etag(path))
+		io.WriteString(w, data)
+		if f.editAfterGet {
+			f.etags[path]++
+		}
+	case http.MethodPut:
+		if r.Header.Get("If-None-Match") == "*" && exists {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && (!exists || match != f.etag(path)) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		f.objects[path] = string(body)
+		f.etags[path]++
+		if exists {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case http.MethodDelete:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.objects, path)
+		delete(f.etags, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+const standupICS = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Test//EN\r\n" +
+	"BEGIN:VEVENT\r\nUID:standup\r\nDTSTAMP:20261001T000000Z\r\n" +
+	"DTSTART:20261020T090000Z\r\nDTEND:20261020T093000Z\r\nSUMMARY:Standup\r\n" +
+	"X-CUSTOM:keep me\r\nATTENDEE;CN=Me:mailto:me@example.com\r\nSEQUENCE:3\r\n" +
+	"END:VEVENT\r\nEND:VCALENDAR\r\n"
+
+const reviewICS = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Test//EN\r\n" +
+	"BEGIN:VEVENT\r\nUID:review\r\nDTSTAMP:20261001T000000Z\r\n" +
+	"DTSTART:20261215T140000Z\r\nDTEND:20261215T150000Z\r\nSUMMARY:Review\r\n" +
+	"END:VEVENT\r\nEND:VCALENDAR\r\n"
+
+// newTestCalDAV starts a fake server holding objects and returns a provider
+// for it.
+func newTestCalDAV(t *testing.T, objects map[string]string) (*fakeCalDAV, *calDAVProvider) {
+	t.Helper()
+	fake := newFakeCalDAV(objects)
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	p := &calDAVProvider{
+		name:     "Work",
+		url:      server.URL + "/cal/",
+		username: "me@example.com",
+		password: "secret",
+		client:   server.Client(),
+	}
+	return fake, p
+}
+
+func TestCalDAVEvents(t *testing.T) {
+	_, p := newTestCalDAV(t, map[string]string{
+		"/cal/standup.ics": standupICS,
+		"/cal/review.ics":  reviewICS,
+	})
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	events, err := p.Events(context.Background(), start, start.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Events() returned %d events, want 1: %+v", len(events), events)
+	}
+	e := events[0]
+	if e.ID != "standup" || e.Summary != "Standup" || e.Href != "/cal/standup.ics" {
+		t.Errorf("Events() = %+v", e)
+	}
+	if want := time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC); !e.Start.Equal(want) {
+		t.Errorf("Start = %v, want %v", e.Start, want)
+	}
+	if len(e.Attendees) != 1 || !e.Attendees[0].Self {
+		t.Errorf("Attendees = %+v, want the user as self", e.Attendees)
+	}
+}
+
+func TestCalDAVEventsErrors(t *testing.T) {
+	_, p := newTestCalDAV(t, nil)
+	p.password = "wrong"
+	if _, err := p.Events(context.Background(), time.Now(), time.Now().AddDate(0, 1, 0)); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Events() error = %v, want 401", err)
+	}
+}
+
+func TestCalDAVCreateEvent(t *testing.T) {
+	fake, p := newTestCalDAV(t, nil)
+	start := time.Date(2026, 11, 3, 10, 0, 0, 0, time.UTC)
+	created, err := p.CreateEvent(context.Background(), Event{Summary: "Lunch", Start: start, End: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || !strings.HasSuffix(created.Href, "/cal/"+created.ID+".ics") {
+		t.Errorf("CreateEvent() = %+v", created)
+	}
+	data, ok := fake.objects["/cal/"+created.ID+".ics"]
+	if !ok || !strings.Contains(data, "SUMMARY:Lunch") || !strings.Contains(data, "UID:"+created.ID) {
+		t.Errorf("server object = %q", data)
+	}
+}
+
+func TestCalDAVUpdateEvent(t *testing.T) {
+	standup := Event{
+		ID:      "standup",
+		Summary: "Daily standup",
+		Start:   time.Date(2026, 10, 20, 9, 15, 0, 0, time.UTC),
+		End:     time.Date(2026, 10, 20, 9, 45, 0, 0, time.UTC),
+		Href:    "/cal/standup.ics",
+	}
+	tests := []struct {
+		name         string
+		event        Event
+		editAfterGet bool
+		wantErr      string
+		wantData     []string
+	}{
+		{
+			name:     "keeps other properties",
+			event:    standup,
+			wantData: []string{"SUMMARY:Daily standup", "DTSTART:20261020T091500Z", "X-CUSTOM:keep me", "ATTENDEE;CN=Me:mailto:me@example.com", "SEQUENCE:4"},
+		},
+		{
+			name:         "changed on the server",
+			event:        standup,
+			editAfterGet: true,
+			wantErr:      "changed on the server",
+			wantData:     []string{"SUMMARY:Standup"},
+		},
+		{
+			name:     "recurring",
+			event:    Event{ID: "standup", Href: "/cal/standup.ics", Recurring: true},
+			wantErr:  errRecurringCalDAV.Error(),
+			wantData: []string{"SUMMARY:Standup"},
+		},
+		{
+			name:    "missing",
+			event:   Event{ID: "gone", Href: "/cal/gone.ics"},
+			wantErr: "404",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, p := newTestCalDAV(t, map[string]string{"/cal/standup.ics": standupICS})
+			fake.editAfterGet = tt.editAfterGet
+			_, err := p.UpdateEvent(context.Background(), tt.event)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("UpdateEvent() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("UpdateEvent() error = %v, want %q", err, tt.wantErr)
+			}
+			data := fake.objects["/cal/standup.ics"]
+			for _, want := range tt.wantData {
+				if !strings.Contains(data, want) {
+					t.Errorf("server object lacks %q:\n%s", want, data)
+				}
+			}
+		})
+	}
+}
+
+func TestCalDAVDeleteEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    Event
+		wantErr  error
+		wantGone bool
+	}{
+		{name: "existing", event: Event{ID: "standup", Href: "/cal/standup.ics"}, wantGone: true},
+		{name: "already deleted", event: Event{ID: "gone", Href: "/cal/gone.ics"}},
+		{name: "recurring", event: Event{ID: "standup", Href: "/cal/standup.ics", Recurring: true}, wantErr: errRecurringCalDAV},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, p := newTestCalDAV(t, map[string]string{"/cal/standup.ics": standupICS})
+			if err := p.DeleteEvent(context.Background(), tt.event); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeleteEvent() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, exists := fake.objects["/cal/standup.ics"]; exists == tt.wantGone {
+				t.Errorf("standup.ics exists = %v, want %v", exists, !tt.wantGone)
+			}
+		})
+	}
+}
+
+func TestMigrateCalDAVPasswords(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+	// Keep the test away from the system keyring.
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path="+filepath.Join(home, "no-bus"))
+	t.Setenv("GODASH_SECRET_STORE", "file")
+	if err := config.EnsureDirs(); err != nil {
+		t.Fatal(err)
+	}
+
+	src := config.CalendarSource{Type: "caldav", Name: "Work", URL: "https://dav.example.com/cal/", Username: "me", Password: "secret"}
+	settings := config.Settings{Location: "Athens", Calendars: []config.CalendarSource{src}}
+	if err := MigrateCalDAVPasswords(&settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings.Calendars[0].Password != "" {
+		t.Error("the settings still hold the password")
+	}
+	saved, err := config.LoadSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Calendars) != 1 || saved.Calendars[0].Password != "" {
+		t.Errorf("config.json holds %+v, want the calendar without its password", saved.Calendars)
+	}
+	if got, err := calDAVPassword(saved.Calendars[0]); err != nil || got != "secret" {
+		t.Errorf("calDAVPassword() = %q, %v, want the stored password", got, err)
+	}
+}
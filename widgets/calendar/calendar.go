@@ -2,44 +2,53 @@ package calendar
 
 import (
 	"context"
-	"encoding/base64"
-	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
-	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
+	"unicode"
 
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/option"
 
 	"GoDash/internal/config"
+	"GoDash/internal/secrets"
 )
 
-//go:embed credentials.json
-var credentialsFile []byte
-
-//go:embed 1761.png
-var logoImage []byte
-
 var (
 	ErrAuthRequired = fmt.Errorf("authentication required")
+	// ErrWriteAccess is returned when GoDash was authorized before it
+	// asked for permission to edit events.
+	ErrWriteAccess = fmt.Errorf("GoDash may only read this calendar: run \"godash signout\" and authorize again to edit events")
+	// ErrListAccess is returned when GoDash was authorized before it asked
+	// for permission to read the list of calendars.
+	ErrListAccess = fmt.Errorf("GoDash may not list your calendars: run \"godash signout\" and authorize again, or configure calendars by ID")
 )
 
-// GetCalendarService creates a new Google Calendar service client.
-// It handles the OAuth 2.0 flow.
-func GetCalendarService() (*calendar.Service, error) {
-	cfg, err := getConfig()
-	if err != nil {
-		return nil, err
+// AuthExpiredError is returned when Google refuses to refresh the token of
+// an account because the sign-in expired or access was revoked, so the
+// account has to be authorized again.
+type AuthExpiredError struct {
+	Account string
+}
+
+func (e *AuthExpiredError) Error() string {
+	if e.Account == "" {
+		return "the Google sign-in expired or was revoked, authorize GoDash again"
 	}
+	return fmt.Sprintf("the Google sign-in of %q expired or was revoked, authorize GoDash again", e.Account)
+}
 
-	client, err := getClient(cfg)
+// GetCalendarService creates a new Google Calendar service client for
+// account. It handles the OAuth 2.0 flow.
+func GetCalendarService(account string) (*calendar.Service, error) {
+	client, err := getClient(account)
 	if err != nil {
 		return nil, err
 	}
@@ -52,290 +61,126 @@ func GetCalendarService() (*calendar.Service, error) {
 	return srv, nil
 }
 
-// getClient retrieves a token, saves the token, then returns the generated client.
-func getClient(cfg *oauth2.Config) (*http.Client, error) {
-	tokFile, err := getTokenPath()
-	if err != nil {
-		return nil, fmt.Errorf("unable to get token path: %v", err)
-	}
-
-	tok, err := tokenFromFile(tokFile)
+// getClient retrieves a token, saves the token, then returns the generated
+// client. The token is refreshed with the OAuth client that issued it.
+func getClient(account string) (*http.Client, error) {
+	tok, err := loadToken(account)
 	if err != nil {
-		return nil, ErrAuthRequired
+		return nil, err
 	}
-	return cfg.Client(context.Background(), tok), nil
-}
-
-// StartAuthFlow starts the OAuth flow, trying automatic server first, then falling back to manual.
-func StartAuthFlow() (string, error) {
-	// Try to start local server for automatic flow
-	go tryStartCallbackServer()
-	
-	// Wait for server to start (or fail)
-	select {
-	case <-serverStarted:
-		// Server started successfully
-		useManualFlow = false
-	case <-time.After(2 * time.Second):
-		// Server failed to start, use manual flow
-		useManualFlow = true
-	}
-	
-	cfg, err := getConfig()
+	cfg, err := oauthConfig(tok.Client)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	authURL := cfg.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	return authURL, nil
+	ts := &savingTokenSource{account: account, client: tok.Client, base: cfg.TokenSource(context.Background(), tok.Token), saved: tok.AccessToken}
+	return oauth2.NewClient(context.Background(), ts), nil
 }
 
-// IsUsingManualFlow returns true if we're using manual code flow
-func IsUsingManualFlow() bool {
-	return useManualFlow
-}
+// savingTokenSource writes the tokens of account back to the secret store
+// whenever they are refreshed, so access tokens are reused across runs.
+type savingTokenSource struct {
+	account string
+	client  string // OAuth client that issued the tokens
+	base    oauth2.TokenSource
 
-// GetAuthURL returns the URL the user needs to visit to authorize the application.
-// This is kept for backward compatibility but now uses the new flow.
-func GetAuthURL() (string, error) {
-	return StartAuthFlow()
+	mu    sync.Mutex
+	saved string // access token in the store
 }
 
-// CompleteAuth exchanges an authorization code for a token and saves it.
-func CompleteAuth(authCode string) error {
-	cfg, err := getConfig()
+func (s *savingTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := s.base.Token()
 	if err != nil {
-		return err
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+			// The refresh token is dead, so the account counts as signed out.
+			deleteToken(s.account)
+			return nil, &AuthExpiredError{Account: s.account}
+		}
+		return nil, err
 	}
 
-	tok, err := cfg.Exchange(context.TODO(), authCode)
-	if err != nil {
-		return fmt.Errorf("unable to retrieve token from web: %v", err)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tok.AccessToken != s.saved {
+		// A token that can't be saved is simply refreshed again next time.
+		if err := saveToken(s.account, s.client, tok); err == nil {
+			s.saved = tok.AccessToken
+		}
 	}
-
-	return saveToken(tok)
+	return tok, nil
 }
 
-var authComplete = make(chan *oauth2.Token, 1)
-var authError = make(chan error, 1)
-
-// tryStartCallbackServer tries to start a callback server on available ports
-func tryStartCallbackServer() {
-	var listener net.Listener
-	var err error
-	
-	// Try each port in our range
-	for _, port := range portRange {
-		listener, err = net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
-		if err == nil {
-			serverPort = port
-			break
-		}
-	}
-	
-	// If no port was available, signal failure
-	if listener == nil {
-		return
-	}
-	
-	defer listener.Close()
-	
-	// Notify that server is ready
-	serverStarted <- true
-
-	mux := http.NewServeMux()
-	mux.HandleFunc("/callback", handleOAuthCallback)
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		http.Redirect(w, r, "/callback"+r.URL.RawQuery, http.StatusTemporaryRedirect)
-	})
-
-	server := &http.Server{Handler: mux}
-	go func() {
-		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
-			log.Printf("Callback server error: %v", err)
-		}
-	}()
-
-	// Wait for auth completion or timeout
-	select {
-	case <-authComplete:
-		server.Shutdown(context.Background())
-	case <-authError:
-		server.Shutdown(context.Background())
-	case <-time.After(5 * time.Minute):
-		log.Println("OAuth timeout")
-		server.Shutdown(context.Background())
-		authError <- fmt.Errorf("authentication timeout")
-	}
+// IsAuthorized checks if the user has a valid token for account.
+func IsAuthorized(account string) bool {
+	_, err := loadToken(account)
+	return err == nil
 }
 
-// handleOAuthCallback handles the OAuth callback from Google
-func handleOAuthCallback(w http.ResponseWriter, r *http.Request) {
-	code := r.URL.Query().Get("code")
-	if code == "" {
-		errorMsg := r.URL.Query().Get("error")
-		if errorMsg != "" {
-			http.Error(w, fmt.Sprintf("OAuth error: %s", errorMsg), http.StatusBadRequest)
-			authError <- fmt.Errorf("oauth error: %s", errorMsg)
-			return
-		}
-		http.Error(w, "No authorization code received", http.StatusBadRequest)
-		authError <- fmt.Errorf("no authorization code received")
-		return
+// tokenKey names the secret holding the token of account.
+func tokenKey(account string) string {
+	if account == "" {
+		return "google-token"
 	}
+	return "google-token:" + account
+}
 
-	// Exchange code for token
-	cfg, err := getConfig()
-	if err != nil {
-		http.Error(w, "Configuration error", http.StatusInternalServerError)
-		authError <- err
-		return
-	}
+// clientDevice names the OAuth client of the device flow in stored tokens.
+const clientDevice = "device"
 
-	tok, err := cfg.Exchange(context.Background(), code)
-	if err != nil {
-		http.Error(w, "Token exchange failed", http.StatusInternalServerError)
-		authError <- err
-		return
-	}
-
-	// Save token
-	if err := saveToken(tok); err != nil {
-		http.Error(w, "Failed to save token", http.StatusInternalServerError)
-		authError <- err
-		return
-	}
-
-	// Encode the logo as base64 for embedding
-	logoBase64 := base64.StdEncoding.EncodeToString(logoImage)
-	
-	// Success page with One Dark theme
-	html := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<head>
-    <title>GoDash - Authentication Successful</title>
-    <style>
-        body { 
-            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', 'JetBrains Mono', monospace, Roboto, sans-serif; 
-            text-align: center; 
-            padding: 50px; 
-            background: #282c34;
-            color: #abb2bf;
-            margin: 0;
-            min-height: 100vh;
-            display: flex;
-            align-items: center;
-            justify-content: center;
-        }
-        .container { 
-            background: #21252b; 
-            padding: 50px 40px; 
-            border-radius: 12px; 
-            box-shadow: 0 10px 30px rgba(0,0,0,0.4);
-            max-width: 500px;
-            border: 1px solid #3e4451;
-            animation: slideUp 0.5s ease-out;
-        }
-        @keyframes slideUp {
-            from { opacity: 0; transform: translateY(30px); }
-            to { opacity: 1; transform: translateY(0); }
-        }
-        .title { 
-            color: #98c379; 
-            font-size: 32px; 
-            margin-bottom: 30px;
-            font-weight: 600;
-        }
-        .logo {
-            margin: 20px 0;
-        }
-        .logo img {
-            max-width: 200px;
-            height: auto;
-        }
-        .message { 
-            color: #abb2bf; 
-            font-size: 18px;
-            line-height: 1.6;
-            margin-bottom: 20px;
-        }
-        .app-name { 
-            color: #61afef; 
-            font-weight: 600; 
-        }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <div class="title">Authentication Successful!</div>
-        <div class="logo">
-            <img src="data:image/png;base64,%s" alt="GoDash Logo" />
-        </div>
-        <div class="message">
-            You can now close this browser window.<br><br>
-            <span class="app-name">GoDash Application</span> has been authorized to access your Google Calendar.
-        </div>
-    </div>
-</body>
-</html>
-`, logoBase64)
-	w.Header().Set("Content-Type", "text/html")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(html))
-
-	authComplete <- tok
+// storedToken is a token as kept in the secret store. Client names the OAuth
+// client that issued it, which is the only one that can refresh it: "" for
+// credentials.json and clientDevice for the device flow's client.
+type storedToken struct {
+	*oauth2.Token
+	Client string `json:"client,omitempty"`
 }
 
-// WaitForAuth waits for the OAuth flow to complete and returns any error
-func WaitForAuth() error {
-	select {
-	case <-authComplete:
-		return nil
-	case err := <-authError:
-		return err
-	case <-time.After(5 * time.Minute):
-		return fmt.Errorf("authentication timeout")
+// oauthConfig returns the config of the OAuth client named client.
+func oauthConfig(client string) (*oauth2.Config, error) {
+	if client == clientDevice {
+		return getDeviceConfig()
 	}
+	return getConfig()
 }
 
-// IsAuthorized checks if the user has a valid token.
-func IsAuthorized() bool {
-	tokFile, err := getTokenPath()
+// loadToken returns the token of account from the secret store, or
+// ErrAuthRequired if GoDash isn't signed in to it.
+func loadToken(account string) (*storedToken, error) {
+	data, err := secrets.Default().Get(tokenKey(account))
+	if errors.Is(err, secrets.ErrNotFound) {
+		return migrateToken(account)
+	}
 	if err != nil {
-		return false
+		return nil, fmt.Errorf("unable to read token: %v", err)
 	}
-	_, err = tokenFromFile(tokFile)
-	return err == nil
+	tok := &storedToken{}
+	if err := json.Unmarshal(data, tok); err != nil {
+		return nil, fmt.Errorf("unable to parse token: %v", err)
+	}
+	if tok.Token == nil {
+		tok.Token = &oauth2.Token{}
+	}
+	return tok, nil
 }
 
-var (
-	serverPort int
-	serverStarted = make(chan bool, 1)
-	useManualFlow bool
-	// Port range to try for OAuth callback server
-	portRange = []int{8080, 8081, 8082, 8083, 8084, 8085, 8086, 8087, 8088, 8089, 8090}
-)
-
-// getConfig loads the OAuth2 config from the embedded credentials file.
-func getConfig() (*oauth2.Config, error) {
-	// For security, the credentials.json file should be provided by the user
-	// and embedded into the application at compile time.
-	// We are providing a placeholder file for now.
-	config, err := google.ConfigFromJSON(credentialsFile, calendar.CalendarReadonlyScope)
+// migrateToken moves the token file written by older versions into the
+// secret store. The file is kept if the store refuses the token.
+func migrateToken(account string) (*storedToken, error) {
+	path, err := getTokenPath(account)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("unable to get token path: %v", err)
 	}
-	
-	// Set redirect URL based on flow type
-	if useManualFlow {
-		// Use out-of-band for manual flow (though deprecated, still works)
-		config.RedirectURL = "urn:ietf:wg:oauth:2.0:oob"
-	} else {
-		// Use localhost with assigned port for automatic flow
-		config.RedirectURL = fmt.Sprintf("http://localhost:%d/callback", serverPort)
+	tok, err := tokenFromFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrAuthRequired
 	}
-	return config, nil
+	if err != nil {
+		return nil, fmt.Errorf("unable to read token file: %v", err)
+	}
+	if err := saveToken(account, "", tok); err == nil {
+		os.Remove(path)
+	}
+	return &storedToken{Token: tok}, nil
 }
 
 // tokenFromFile retrieves a token from a local file.
@@ -350,46 +195,58 @@ func tokenFromFile(file string) (*oauth2.Token, error) {
 	return tok, err
 }
 
-// saveToken saves a token to a file path.
-func saveToken(token *oauth2.Token) error {
-	path, err := getTokenPath()
+// saveToken saves the token of account, issued by the OAuth client named
+// client, in the secret store.
+func saveToken(account, client string, token *oauth2.Token) error {
+	data, err := json.Marshal(storedToken{Token: token, Client: client})
 	if err != nil {
-		return fmt.Errorf("unable to get token path: %v", err)
+		return fmt.Errorf("unable to encode oauth token: %v", err)
 	}
-	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err := secrets.Default().Set(tokenKey(account), data); err != nil {
+		return fmt.Errorf("unable to store oauth token: %v", err)
+	}
+	return nil
+}
+
+// deleteToken removes the token of account, including a token file that
+// wasn't migrated yet.
+func deleteToken(account string) error {
+	if err := secrets.Default().Delete(tokenKey(account)); err != nil {
+		return fmt.Errorf("unable to delete token: %v", err)
+	}
+	path, err := getTokenPath(account)
 	if err != nil {
-		return fmt.Errorf("unable to cache oauth token: %v", err)
+		return fmt.Errorf("unable to get token path: %v", err)
 	}
-	defer f.Close()
-	return json.NewEncoder(f).Encode(token)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("unable to delete token: %v", err)
+	}
+	return nil
 }
 
-// getTokenPath returns the path to the token.json file.
-func getTokenPath() (string, error) {
+// getTokenPath returns the path to the token file older versions kept for
+// account: token.json for the default account and token-<account>.json for
+// the others.
+func getTokenPath(account string) (string, error) {
 	configDir, err := config.GetConfigDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(configDir, "token.json"), nil
+	if account == "" {
+		return filepath.Join(configDir, "token.json"), nil
+	}
+	return filepath.Join(configDir, "token-"+tokenFileName(account)+".json"), nil
 }
 
-// GetCalendarEvents fetches events for a specific day from the user's primary calendar.
-func GetCalendarEvents(srv *calendar.Service, day time.Time) ([]*calendar.Event, error) {
-	startOfDay := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
-
-	events, err := srv.Events.List("primary").
-		ShowDeleted(false).
-		SingleEvents(true).
-		TimeMin(startOfDay.Format(time.RFC3339)).
-		TimeMax(endOfDay.Format(time.RFC3339)).
-		OrderBy("startTime").
-		Do()
-
-	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve events for the selected day: %v", err)
-	}
-	return events.Items, nil
+// tokenFileName replaces the characters of account that don't belong in a
+// file name.
+func tokenFileName(account string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(".@-_", r) {
+			return r
+		}
+		return '_'
+	}, account)
 }
 
 // --- Caching Functions ---
@@ -402,63 +259,87 @@ func getCalendarCachePath() (string, error) {
 	return filepath.Join(cacheDir, "calendar_cache.json"), nil
 }
 
-// LoadCalendarCache reads the event cache from disk.
-func LoadCalendarCache() (map[string][]*calendar.Event, error) {
+// calendarCache is the format of calendar_cache.json.
+type calendarCache struct {
+	Months map[string]cachedMonth `json:"months"`
+}
+
+// cachedMonth holds the events of a month and when they were fetched.
+type cachedMonth struct {
+	Fetched time.Time `json:"fetched"`
+	Events  []Event   `json:"events"`
+}
+
+// LoadCalendarCache reads the event cache from disk, returning the events and
+// the time they were fetched by month. Caches written before fetch times were
+// kept are loaded as fetched long ago.
+func LoadCalendarCache() (map[string][]Event, map[string]time.Time, error) {
+	events := make(map[string][]Event)
+	fetched := make(map[string]time.Time)
+
 	path, err := getCalendarCachePath()
 	if err != nil {
-		return nil, fmt.Errorf("unable to get calendar cache path: %v", err)
+		return nil, nil, fmt.Errorf("unable to get calendar cache path: %v", err)
 	}
 
 	content, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Cache file doesn't exist, return an empty cache
-			return make(map[string][]*calendar.Event), nil
+			return events, fetched, nil
 		}
-		return nil, fmt.Errorf("could not read calendar cache file: %v", err)
+		return nil, nil, fmt.Errorf("could not read calendar cache file: %v", err)
 	}
 
-	var cache map[string][]*calendar.Event
+	var cache calendarCache
 	err = json.Unmarshal(content, &cache)
+	if err == nil && cache.Months == nil {
+		err = json.Unmarshal(content, &events)
+	}
 	if err != nil {
 		// If unmarshalling fails, maybe the file is corrupt. Return an empty cache and log the error.
 		fmt.Printf("Warning: could not unmarshal calendar cache, starting fresh: %v\n", err)
-		return make(map[string][]*calendar.Event), nil
+		return make(map[string][]Event), fetched, nil
 	}
 
-	return cache, nil
+	for monthKey, month := range cache.Months {
+		events[monthKey] = month.Events
+		fetched[monthKey] = month.Fetched
+	}
+	return events, fetched, nil
 }
 
-// SaveCalendarCache writes the event cache to disk.
-func SaveCalendarCache(cache map[string][]*calendar.Event) error {
+// SaveCalendarCache writes the event cache and the fetch times to disk. The
+// file is replaced atomically, so readers never see a partial write.
+func SaveCalendarCache(events map[string][]Event, fetched map[string]time.Time) error {
 	path, err := getCalendarCachePath()
 	if err != nil {
 		return fmt.Errorf("unable to get calendar cache path: %v", err)
 	}
 
+	cache := calendarCache{Months: make(map[string]cachedMonth)}
+	for monthKey, monthEvents := range events {
+		cache.Months[monthKey] = cachedMonth{Fetched: fetched[monthKey], Events: monthEvents}
+	}
 	data, err := json.MarshalIndent(cache, "", "  ")
 	if err != nil {
 		return fmt.Errorf("could not marshal calendar cache: %v", err)
 	}
 
-	return os.WriteFile(path, data, 0644)
-}
-
-// GetCalendarEventsForMonth fetches events for a specific month from the user's primary calendar.
-func GetCalendarEventsForMonth(srv *calendar.Service, month time.Time) ([]*calendar.Event, error) {
-	firstDayOfMonth := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
-	firstDayOfNextMonth := firstDayOfMonth.AddDate(0, 1, 0)
-
-	events, err := srv.Events.List("primary").
-		ShowDeleted(false).
-		SingleEvents(true).
-		TimeMin(firstDayOfMonth.Format(time.RFC3339)).
-		TimeMax(firstDayOfNextMonth.Format(time.RFC3339)).
-		OrderBy("startTime").
-		Do()
-
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".calendar_cache-*.json")
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve events for the selected month: %v", err)
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
 	}
-	return events.Items, nil
+	return os.Rename(tmp.Name(), path)
 }
@@ -0,0 +1,84 @@
+package calendar
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestSaveCacheKeepsNewestSnapshot(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+	if err := os.MkdirAll(filepath.Join(cacheHome, "GoDash"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	m := Model{cachedEvents: make(map[string][]Event), fetchedAt: make(map[string]time.Time)}
+	const saves = 50
+	for i := 1; i <= saves; i++ {
+		m.cachedEvents["2026-10"] = []Event{{ID: strconv.Itoa(i), Summary: "Save " + strconv.Itoa(i)}}
+		m.saveCache()
+	}
+	last := cacheWrites.taken.Load()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		cacheWrites.Lock()
+		written := cacheWrites.written
+		cacheWrites.Unlock()
+		if written == last {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("snapshot %d was never written, last written is %d", last, written)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	events, _, err := LoadCalendarCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := events["2026-10"]; len(got) != 1 || got[0].ID != strconv.Itoa(saves) {
+		t.Errorf("cached events = %+v, want save %d", got, saves)
+	}
+	entries, err := os.ReadDir(filepath.Join(cacheHome, "GoDash"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "calendar_cache.json" {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("cache directory holds %v, want only calendar_cache.json", names)
+	}
+}
+
+func TestStoredTokenClient(t *testing.T) {
+	expiry := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	data, err := json.Marshal(storedToken{Token: &oauth2.Token{AccessToken: "a", RefreshToken: "r", Expiry: expiry}, Client: clientDevice})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tok storedToken
+	if err := json.Unmarshal(data, &tok); err != nil {
+		t.Fatal(err)
+	}
+	if tok.Client != clientDevice || tok.RefreshToken != "r" || !tok.Expiry.Equal(expiry) {
+		t.Errorf("token read back as %s", data)
+	}
+
+	// Tokens saved before the client was recorded came from credentials.json.
+	var old storedToken
+	if err := json.Unmarshal([]byte(`{"access_token":"a","refresh_token":"r"}`), &old); err != nil {
+		t.Fatal(err)
+	}
+	if old.Client != "" || old.Token == nil || old.RefreshToken != "r" {
+		t.Errorf("old token read as %+v", old)
+	}
+}
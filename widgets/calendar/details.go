@@ -0,0 +1,119 @@
+package calendar
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// conferencePattern matches links to the common video call services, which
+// are often only mentioned in the location or description.
+var conferencePattern = regexp.MustCompile(`https://(?:` +
+	`meet\.google\.com/[a-z-]+` +
+	`|(?:[\w-]+\.)?zoom\.us/(?:j|my|w)/[^\s"'<>)\]]+` +
+	`|teams\.microsoft\.com/l/meetup-join/[^\s"'<>)\]]+` +
+	`|teams\.live\.com/meet/[^\s"'<>)\]]+` +
+	`|(?:[\w-]+\.)?webex\.com/(?:meet|join|[\w-]+/j\.php)[^\s"'<>)\]]*` +
+	`|meet\.jit\.si/[^\s"'<>)\]]+` +
+	`|whereby\.com/[^\s"'<>)\]]+)`)
+
+// findConferenceURL returns the first video call link in texts.
+func findConferenceURL(texts ...string) string {
+	for _, text := range texts {
+		if url := conferencePattern.FindString(text); url != "" {
+			return url
+		}
+	}
+	return ""
+}
+
+// ResponseIcon returns a symbol for an attendee's response status.
+func ResponseIcon(status string) string {
+	switch status {
+	case "accepted":
+		return "✔"
+	case "declined":
+		return "✘"
+	case "tentative":
+		return "?"
+	}
+	return "·"
+}
+
+// TimeRange describes when the event takes place in local time, e.g.
+// "Tue 5 Mar 2024, 10:00 – 11:30" or "Fri 1 – Sun 3 Mar 2024 (all day)".
+func (e Event) TimeRange() string {
+	start, end := e.Start.Local(), e.End.Local()
+	const dayLayout = "Mon 2 Jan 2006"
+	if e.AllDay {
+		last := end.AddDate(0, 0, -1)
+		if !last.After(start) {
+			return start.Format(dayLayout) + " (all day)"
+		}
+		return dayRange(start, last) + " (all day)"
+	}
+	if !end.After(start) {
+		return start.Format(dayLayout + ", 15:04")
+	}
+	if sameDay(start, end) {
+		return start.Format(dayLayout+", 15:04") + " – " + end.Format("15:04")
+	}
+	return start.Format(dayLayout+", 15:04") + " – " + end.Format(dayLayout+", 15:04")
+}
+
+// dayRange formats two dates, leaving out the month and year they share.
+func dayRange(first, last time.Time) string {
+	switch {
+	case first.Year() != last.Year():
+		return first.Format("Mon 2 Jan 2006") + " – " + last.Format("Mon 2 Jan 2006")
+	case first.Month() != last.Month():
+		return first.Format("Mon 2 Jan") + " – " + last.Format("Mon 2 Jan 2006")
+	}
+	return first.Format("Mon 2") + " – " + last.Format("Mon 2 Jan 2006")
+}
+
+var (
+	htmlPattern       = regexp.MustCompile(`(?i)</?(?:a|b|br|div|em|i|li|ol|p|span|strong|u|ul)[\s/>]`)
+	htmlTagPattern    = regexp.MustCompile(`(?i)</?[a-z][^>]*>`)
+	htmlLinkPattern   = regexp.MustCompile(`(?is)<a\s[^>]*href=["']([^"']+)["'][^>]*>(.*?)</a>`)
+	htmlBreakPattern  = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlBlockPattern  = regexp.MustCompile(`(?i)</?(?:p|div|ul|ol)(?:\s[^>]*)?>`)
+	htmlItemPattern   = regexp.MustCompile(`(?i)<li(?:\s[^>]*)?>`)
+	htmlBoldPattern   = regexp.MustCompile(`(?i)</?(?:b|strong)>`)
+	htmlItalicPattern = regexp.MustCompile(`(?i)</?(?:i|em)>`)
+	blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// DescriptionMarkdown returns the description as Markdown. Google Calendar
+// stores descriptions written in its web editor as HTML, which is converted
+// to the Markdown equivalents; plain text is returned as it is.
+func (e Event) DescriptionMarkdown() string {
+	desc := strings.TrimSpace(e.Description)
+	if !htmlPattern.MatchString(desc) {
+		return desc
+	}
+	desc = strings.ReplaceAll(desc, "\n", " ")
+	desc = htmlLinkPattern.ReplaceAllStringFunc(desc, func(a string) string {
+		m := htmlLinkPattern.FindStringSubmatch(a)
+		text := strings.TrimSpace(htmlTagPattern.ReplaceAllString(m[2], ""))
+		if text == "" || text == m[1] {
+			return "<" + m[1] + ">"
+		}
+		return "[" + text + "](" + m[1] + ")"
+	})
+	desc = htmlBreakPattern.ReplaceAllString(desc, "\n")
+	desc = htmlItemPattern.ReplaceAllString(desc, "\n- ")
+	desc = htmlBlockPattern.ReplaceAllString(desc, "\n\n")
+	desc = htmlBoldPattern.ReplaceAllString(desc, "**")
+	desc = htmlItalicPattern.ReplaceAllString(desc, "_")
+	// Keep the <url> autolinks made above while dropping other tags.
+	desc = htmlTagPattern.ReplaceAllStringFunc(desc, func(tag string) string {
+		if strings.HasPrefix(tag, "<http") {
+			return tag
+		}
+		return ""
+	})
+	desc = html.UnescapeString(desc)
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(desc, "\n\n"))
+}
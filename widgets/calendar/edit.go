@@ -0,0 +1,306 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type eventOp int
+
+const (
+	opCreate eventOp = iota
+	opUpdate
+	opDelete
+)
+
+// pendingPrefix marks the IDs of created events the provider hasn't
+// confirmed yet.
+const pendingPrefix = "pending-"
+
+// eventWrittenMsg reports the result of a change made from the calendar.
+// old and new are the cached events before and after the optimistic update.
+type eventWrittenMsg struct {
+	op       eventOp
+	old, new Event
+	saved    Event // the event as stored by the provider
+	err      error
+}
+
+// writeEvent sends a change to the provider.
+func writeEvent(w EventWriter, op eventOp, old, new Event) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		msg := eventWrittenMsg{op: op, old: old, new: new}
+		switch op {
+		case opCreate:
+			msg.saved, msg.err = w.CreateEvent(ctx, new)
+		case opUpdate:
+			msg.saved, msg.err = w.UpdateEvent(ctx, new)
+		case opDelete:
+			msg.err = w.DeleteEvent(ctx, old)
+		}
+		return msg
+	}
+}
+
+// IsEditing reports whether the event form or a delete confirmation is
+// open, in which case the calendar needs all key presses.
+func (m *Model) IsEditing() bool {
+	return m.form != nil || m.confirmDelete
+}
+
+// SelectedEvent returns the event selected in the day list.
+func (m *Model) SelectedEvent() (Event, bool) {
+	if m.selected < 0 || m.selected >= len(m.events) {
+		return Event{}, false
+	}
+	return m.events[m.selected], true
+}
+
+// editableEvent returns the selected event if its calendar can be changed,
+// explaining why not in the status line otherwise.
+func (m *Model) editableEvent() (Event, EventWriter, bool) {
+	event, ok := m.SelectedEvent()
+	if !ok {
+		return Event{}, nil, false
+	}
+	if strings.HasPrefix(event.ID, pendingPrefix) {
+		m.setStatus("The event is still being saved", false)
+		return Event{}, nil, false
+	}
+	w, ok := writerFor(m.providers, event.Calendar)
+	if !ok {
+		m.setStatus(fmt.Sprintf("%s is read-only", event.Calendar), true)
+		return Event{}, nil, false
+	}
+	return event, w, true
+}
+
+func (m *Model) setStatus(status string, isErr bool) {
+	m.status = status
+	m.statusErr = isErr
+}
+
+// updateEditing handles the keys for selecting, adding, editing and deleting
+// events. It reports whether msg was used.
+func (m *Model) updateEditing(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, m.keys.NextEvent):
+		if m.selected < len(m.events)-1 {
+			m.selected++
+		}
+		return nil, true
+	case key.Matches(msg, m.keys.PrevEvent):
+		if m.selected > 0 {
+			m.selected--
+		}
+		return nil, true
+	case key.Matches(msg, m.keys.AddEvent):
+		calendars := writers(m.providers)
+		if len(calendars) == 0 {
+			m.setStatus("None of the calendars can be edited", true)
+			return nil, true
+		}
+		m.form = newEventForm(m.selectedDate, calendars)
+		return textinput.Blink, true
+	case key.Matches(msg, m.keys.EditEvent):
+		if event, _, ok := m.editableEvent(); ok {
+			m.form = editEventForm(event)
+			return textinput.Blink, true
+		}
+		return nil, true
+	case key.Matches(msg, m.keys.DeleteEvent):
+		if event, _, ok := m.editableEvent(); ok {
+			m.confirmDelete = true
+			m.setStatus(fmt.Sprintf("Delete %q? (y/n)", event.Summary), false)
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+// updateForm passes msg to the open form, saving or closing it on confirm
+// and cancel.
+func (m *Model) updateForm(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keys.Cancel):
+			m.form = nil
+			return nil
+		case key.Matches(msg, m.keys.Confirm):
+			return m.submitForm()
+		}
+	}
+	return m.form.Update(msg)
+}
+
+// updateConfirmDelete waits for the answer to the delete question.
+func (m *Model) updateConfirmDelete(msg tea.Msg) tea.Cmd {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return nil
+	}
+	m.confirmDelete = false
+	m.status = ""
+	if !key.Matches(keyMsg, key.NewBinding(key.WithKeys("y", "Y"))) {
+		return nil
+	}
+	event, w, ok := m.editableEvent()
+	if !ok {
+		return nil
+	}
+	m.replaceCachedEvent(&event, nil)
+	m.afterCacheChange()
+	m.pendingWrites++
+	return writeEvent(w, opDelete, event, Event{})
+}
+
+// submitForm updates the cache right away and sends the change to the
+// provider; failures are rolled back when the result arrives.
+func (m *Model) submitForm() tea.Cmd {
+	event, err := m.form.event()
+	if err != nil {
+		m.form.err = err.Error()
+		return nil
+	}
+	w, ok := writerFor(m.providers, event.Calendar)
+	if !ok {
+		m.form.err = fmt.Sprintf("%s is read-only", event.Calendar)
+		return nil
+	}
+
+	var cmd tea.Cmd
+	if original := m.form.original; original != nil {
+		m.replaceCachedEvent(original, &event)
+		cmd = writeEvent(w, opUpdate, *original, event)
+	} else {
+		event.ID = pendingPrefix + strconv.FormatInt(time.Now().UnixNano(), 36)
+		m.replaceCachedEvent(nil, &event)
+		cmd = writeEvent(w, opCreate, Event{}, event)
+	}
+	m.form = nil
+	m.afterCacheChange()
+	m.selectEvent(event)
+	m.pendingWrites++
+	return cmd
+}
+
+// finishWrite replaces the optimistic event with the one stored by the
+// provider, or restores the previous state if the change failed.
+func (m *Model) finishWrite(msg eventWrittenMsg) {
+	m.pendingWrites--
+	if msg.err != nil {
+		switch msg.op {
+		case opCreate:
+			m.replaceCachedEvent(&msg.new, nil)
+		case opUpdate:
+			m.replaceCachedEvent(&msg.new, &msg.old)
+		case opDelete:
+			m.replaceCachedEvent(nil, &msg.old)
+		}
+		action := "save"
+		if msg.op == opDelete {
+			action = "delete"
+		}
+		m.setStatus(fmt.Sprintf("Couldn't %s the event: %v", action, msg.err), true)
+	} else {
+		switch msg.op {
+		case opCreate, opUpdate:
+			msg.saved.Calendar = msg.new.Calendar
+			m.replaceCachedEvent(&msg.new, &msg.saved)
+			m.setStatus("Event saved", false)
+		case opDelete:
+			m.setStatus("Event deleted", false)
+		}
+	}
+	m.afterCacheChange()
+}
+
+// replaceCachedEvent removes old from the cached months and adds new to the
+// cached months it overlaps. Either may be nil. Events are matched by
+// calendar and ID, and new also replaces an earlier copy of itself, e.g. one
+// that arrived with a refresh while the change was being saved.
+func (m *Model) replaceCachedEvent(old, new *Event) {
+	for monthKey, events := range m.cachedEvents {
+		monthStart, err := time.ParseInLocation("2006-01", monthKey, time.Local)
+		if err != nil {
+			continue
+		}
+		kept := make([]Event, 0, len(events)+1)
+		for _, e := range events {
+			if (old != nil && e.sameAs(*old)) || (new != nil && e.sameAs(*new)) {
+				continue
+			}
+			kept = append(kept, e)
+		}
+		if new != nil && new.overlaps(monthStart, monthStart.AddDate(0, 1, 0)) {
+			kept = append(kept, *new)
+			sort.SliceStable(kept, func(i, j int) bool { return kept[i].Start.Before(kept[j].Start) })
+		}
+		m.cachedEvents[monthKey] = kept
+	}
+}
+
+// afterCacheChange refreshes the day list and saves the cache.
+func (m *Model) afterCacheChange() {
+	m.filterEventsForSelectedDate()
+	m.saveCache()
+}
+
+// cacheWrites serializes the background writes of the cache. Snapshots are
+// numbered in the order they are taken, so a write that lost the race for
+// the lock never replaces a newer snapshot.
+var cacheWrites struct {
+	sync.Mutex
+	taken   atomic.Uint64
+	written uint64
+}
+
+// saveCache writes copies of the cache maps to disk in the background, as
+// the maps keep changing while the file is written.
+func (m *Model) saveCache() {
+	events := make(map[string][]Event, len(m.cachedEvents))
+	for monthKey, monthEvents := range m.cachedEvents {
+		events[monthKey] = monthEvents
+	}
+	fetched := make(map[string]time.Time, len(m.fetchedAt))
+	for monthKey, t := range m.fetchedAt {
+		fetched[monthKey] = t
+	}
+	snapshot := cacheWrites.taken.Add(1)
+	go func() {
+		cacheWrites.Lock()
+		defer cacheWrites.Unlock()
+		if snapshot < cacheWrites.written {
+			return
+		}
+		if SaveCalendarCache(events, fetched) == nil {
+			cacheWrites.written = snapshot
+		}
+	}()
+}
+
+// selectEvent moves the selection to e if it is shown for the selected day.
+func (m *Model) selectEvent(e Event) {
+	for i, event := range m.events {
+		if event.sameAs(e) {
+			m.selected = i
+			return
+		}
+	}
+}
+
+func (e Event) sameAs(other Event) bool {
+	return e.Calendar == other.Calendar && e.ID == other.ID
+}
@@ -0,0 +1,235 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"GoDash/internal/config"
+)
+
+// LoadEvents returns the events of the configured calendars overlapping
+// [start, end) from the calendar cache. Months that aren't cached are
+// fetched first and saved to the cache, as when the calendar shows them.
+func LoadEvents(ctx context.Context, sources []config.CalendarSource, start, end time.Time) ([]Event, error) {
+	providers, err := NewProviders(sources)
+	if err != nil {
+		return nil, err
+	}
+	cachedEvents, fetchedAt, err := LoadCalendarCache()
+	if err != nil {
+		cachedEvents = make(map[string][]Event)
+		fetchedAt = make(map[string]time.Time)
+	}
+	m := Model{
+		providers:      providers,
+		cachedEvents:   cachedEvents,
+		fetchedAt:      fetchedAt,
+		fetchingMonths: make(map[string]bool),
+	}
+	if err := m.fetchMissingMonths(ctx, start, end); err != nil {
+		return nil, err
+	}
+	return m.eventsBetween(start, end), nil
+}
+
+// fetchMissingMonths fetches the months of [start, end) that aren't cached
+// and saves them to the cache.
+func (m *Model) fetchMissingMonths(ctx context.Context, start, end time.Time) error {
+	fetched := false
+	month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.Local)
+	for ; month.Before(end); month = month.AddDate(0, 1, 0) {
+		monthKey := month.Format("2006-01")
+		if _, ok := m.cachedEvents[monthKey]; ok {
+			continue
+		}
+		started := time.Now()
+		events, err := fetchEvents(ctx, m.providers, month, month.AddDate(0, 1, 0))
+		if err != nil {
+			return fmt.Errorf("could not fetch events of %s: %w", month.Format("January 2006"), err)
+		}
+		m.cachedEvents[monthKey] = events
+		m.fetchedAt[monthKey] = started
+		fetched = true
+	}
+	if fetched {
+		return SaveCalendarCache(m.cachedEvents, m.fetchedAt)
+	}
+	return nil
+}
+
+// eventsBetween returns the cached events overlapping [start, end) sorted
+// by start. Events spanning months are cached in each of them but returned
+// once.
+func (m *Model) eventsBetween(start, end time.Time) []Event {
+	seen := make(map[string]bool)
+	var events []Event
+	month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.Local)
+	for ; month.Before(end); month = month.AddDate(0, 1, 0) {
+		for _, e := range m.cachedEvents[month.Format("2006-01")] {
+			key := occurrenceKey(e)
+			if seen[key] || !e.overlaps(start, end) {
+				continue
+			}
+			seen[key] = true
+			events = append(events, e)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+	return events
+}
+
+// icsPartStatOf maps the response statuses of Attendee to PARTSTAT values.
+var icsPartStatOf = map[string]string{
+	"accepted":    "ACCEPTED",
+	"declined":    "DECLINED",
+	"tentative":   "TENTATIVE",
+	"needsAction": "NEEDS-ACTION",
+}
+
+// ExportICS writes events as an iCalendar file. Occurrences of recurring
+// events are written as separate events, with their start added to the UID
+// so calendars importing the file keep them all.
+func ExportICS(w io.Writer, events []Event) error {
+	root := &icsComponent{
+		Name: "VCALENDAR",
+		Props: []icsProperty{
+			{Name: "VERSION", Value: "2.0"},
+			{Name: "PRODID", Value: "-//GoDash//GoDash//EN"},
+		},
+	}
+	for _, e := range events {
+		root.Components = append(root.Components, exportedEvent(e))
+	}
+	return writeICS(w, root)
+}
+
+// exportedEvent converts e to a VEVENT.
+func exportedEvent(e Event) *icsComponent {
+	uid := e.ID
+	if uid == "" {
+		uid = newUID()
+	}
+	if e.Recurring {
+		uid += "-" + e.Start.UTC().Format("20060102T150405Z")
+	}
+	vevent := &icsComponent{Name: "VEVENT"}
+	vevent.set(icsProperty{Name: "UID", Value: uid})
+	vevent.setEvent(e)
+	if e.Calendar != "" {
+		vevent.setText("CATEGORIES", e.Calendar)
+	}
+	if e.URL != "" {
+		vevent.set(icsProperty{Name: "URL", Value: e.URL})
+	}
+	if e.ConferenceURL != "" {
+		vevent.set(icsProperty{Name: "CONFERENCE", Params: map[string]string{"VALUE": "URI"}, Value: e.ConferenceURL})
+	}
+	if e.Color != "" {
+		vevent.set(icsProperty{Name: "COLOR", Value: e.Color})
+	}
+	for _, a := range e.Attendees {
+		if a.Email == "" {
+			continue
+		}
+		if a.Organizer {
+			vevent.Props = append(vevent.Props, icsProperty{Name: "ORGANIZER", Params: icsNameParams(a.Name), Value: "mailto:" + a.Email})
+		}
+		params := icsNameParams(a.Name)
+		if status, ok := icsPartStatOf[a.Status]; ok {
+			params["PARTSTAT"] = status
+		}
+		if a.Optional {
+			params["ROLE"] = "OPT-PARTICIPANT"
+		}
+		vevent.Props = append(vevent.Props, icsProperty{Name: "ATTENDEE", Params: params, Value: "mailto:" + a.Email})
+	}
+	alarmText := e.Summary
+	if alarmText == "" {
+		alarmText = "Reminder"
+	}
+	for _, minutes := range e.Reminders {
+		vevent.Components = append(vevent.Components, &icsComponent{
+			Name: "VALARM",
+			Props: []icsProperty{
+				{Name: "ACTION", Value: "DISPLAY"},
+				{Name: "DESCRIPTION", Value: escapeICSText(alarmText)},
+				{Name: "TRIGGER", Value: "-PT" + strconv.Itoa(minutes) + "M"},
+			},
+		})
+	}
+	return vevent
+}
+
+// icsNameParams returns the CN parameter of a calendar user, if named.
+func icsNameParams(name string) map[string]string {
+	params := make(map[string]string)
+	if name != "" {
+		params["CN"] = name
+	}
+	return params
+}
+
+// ExportAgenda writes the events of the days from start to end as a plain
+// text agenda, or as markdown:
+//
+//	Sat 18 Oct 2026
+//	  09:00–09:30  Standup · Room 2
+//	  all day      Conference
+//
+// Days without events are left out.
+func ExportAgenda(w io.Writer, events []Event, start, end time.Time, markdown bool) error {
+	var b strings.Builder
+	for day := dayStart(start); day.Before(end); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		var lines []string
+		for _, e := range events {
+			if !e.overlaps(day, dayEnd) {
+				continue
+			}
+			lines = append(lines, agendaTextLine(e, day, markdown))
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		if markdown {
+			b.WriteString("## " + day.Format("Mon 2 Jan 2006") + "\n\n")
+		} else {
+			b.WriteString(day.Format("Mon 2 Jan 2006") + "\n")
+		}
+		for _, line := range lines {
+			b.WriteString(line + "\n")
+		}
+	}
+	if b.Len() == 0 {
+		b.WriteString("No events\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// agendaTextLine formats an event of day for ExportAgenda.
+func agendaTextLine(e Event, day time.Time, markdown bool) string {
+	when := "all day"
+	if !e.AllDay {
+		when = agendaTime(e, day)
+	}
+	summary := strings.ReplaceAll(e.Summary, "\n", " ")
+	if summary == "" {
+		summary = "(No title)"
+	}
+	if e.Location != "" {
+		summary += " · " + strings.ReplaceAll(e.Location, "\n", " ")
+	}
+	if markdown {
+		return "- **" + strings.TrimSpace(when) + "** " + summary
+	}
+	return "  " + fmt.Sprintf("%-*s", agendaTimeWidth, when) + "  " + summary
+}
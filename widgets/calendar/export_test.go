@@ -0,0 +1,147 @@
+package calendar
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportAgenda(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.Local)
+	}
+	events := []Event{
+		{Summary: "Conference", Start: at(19, 0, 0), End: at(21, 0, 0), AllDay: true},
+		{Summary: "Standup", Location: "Room 2", Start: at(19, 9, 0), End: at(19, 9, 30)},
+		{Summary: "", Start: at(20, 14, 0), End: at(20, 14, 0)},
+		{Summary: "Night\nshift", Start: at(20, 22, 0), End: at(21, 6, 0)},
+	}
+	tests := []struct {
+		name     string
+		events   []Event
+		markdown bool
+		want     string
+	}{
+		{
+			name:   "text",
+			events: events,
+			want: "Mon 19 Oct 2026\n" +
+				"  all day      Conference\n" +
+				"  09:00–09:30  Standup · Room 2\n" +
+				"\n" +
+				"Tue 20 Oct 2026\n" +
+				"  all day      Conference\n" +
+				"  14:00        (No title)\n" +
+				"  22:00–…      Night shift\n" +
+				"\n" +
+				"Wed 21 Oct 2026\n" +
+				"  …–06:00      Night shift\n",
+		},
+		{
+			name:     "markdown",
+			events:   events[:2],
+			markdown: true,
+			want: "## Mon 19 Oct 2026\n\n" +
+				"- **all day** Conference\n" +
+				"- **09:00–09:30** Standup · Room 2\n" +
+				"\n" +
+				"## Tue 20 Oct 2026\n\n" +
+				"- **all day** Conference\n",
+		},
+		{
+			name: "no events",
+			want: "No events\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := ExportAgenda(&b, tt.events, at(18, 0, 0), at(25, 0, 0), tt.markdown); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("ExportAgenda() =\n%s\nwant\n%s", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestExportICS(t *testing.T) {
+	start := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	events := []Event{
+		{
+			ID:        "standup",
+			Calendar:  "Work",
+			Summary:   "Standup, daily; short",
+			Location:  "Room 2",
+			Start:     start,
+			End:       start.Add(30 * time.Minute),
+			Recurring: true,
+			Reminders: []int{5, 15},
+			Attendees: []Attendee{
+				{Name: "Alice", Email: "alice@example.com", Status: "accepted", Organizer: true},
+				{Email: "bob@example.com", Status: "tentative", Optional: true},
+				{Name: "No email"},
+			},
+		},
+		{ID: "standup", Calendar: "Work", Summary: "Standup, daily; short", Start: start.AddDate(0, 0, 1), End: start.AddDate(0, 0, 1).Add(30 * time.Minute), Recurring: true},
+		{ID: "trip", Summary: "Trip", Start: time.Date(2026, 10, 22, 0, 0, 0, 0, time.Local), End: time.Date(2026, 10, 24, 0, 0, 0, 0, time.Local), AllDay: true},
+	}
+	var b strings.Builder
+	if err := ExportICS(&b, events); err != nil {
+		t.Fatal(err)
+	}
+	data := b.String()
+
+	roots, err := parseICS(strings.NewReader(data))
+	if err != nil || len(roots) != 1 {
+		t.Fatalf("parseICS() = %d calendars, %v", len(roots), err)
+	}
+	var uids []string
+	for _, comp := range roots[0].Components {
+		uids = append(uids, comp.text("UID"))
+	}
+	wantUIDs := []string{"standup-20261019T090000Z", "standup-20261020T090000Z", "trip"}
+	if !reflect.DeepEqual(uids, wantUIDs) {
+		t.Errorf("UIDs = %v, want %v", uids, wantUIDs)
+	}
+
+	for _, want := range []string{
+		"PRODID:-//GoDash//GoDash//EN",
+		`SUMMARY:Standup\, daily\; short`,
+		"CATEGORIES:Work",
+		"ORGANIZER;CN=Alice:mailto:alice@example.com",
+		"PARTSTAT=ACCEPTED",
+		"ROLE=OPT-PARTICIPANT",
+		"TRIGGER:-PT15M",
+		"DTSTART;VALUE=DATE:20261022",
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("export lacks %q:\n%s", want, data)
+		}
+	}
+	if strings.Contains(data, "No email") {
+		t.Error("attendees without an email are exported")
+	}
+
+	// The export reads back as the same events.
+	parsed, err := eventsFromICSData(strings.NewReader(data), start.AddDate(0, 0, -1), start.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(parsed, func(i, j int) bool { return parsed[i].Start.Before(parsed[j].Start) })
+	if len(parsed) != len(events) {
+		t.Fatalf("read back %d events, want %d", len(parsed), len(events))
+	}
+	for i, e := range parsed {
+		want := events[i]
+		if e.Summary != want.Summary || !e.Start.Equal(want.Start) || !e.End.Equal(want.End) || e.AllDay != want.AllDay {
+			t.Errorf("event %d read back as %+v, want %+v", i, e, want)
+		}
+	}
+	if got := parsed[0].Reminders; !reflect.DeepEqual(got, []int{5, 15}) {
+		t.Errorf("reminders read back as %v, want [5 15]", got)
+	}
+}
@@ -0,0 +1,317 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	formDateLayout     = "2006-01-02"
+	formDateTimeLayout = "2006-01-02 15:04"
+	formTimeLayout     = "15:04"
+)
+
+type formField int
+
+// Fields in the order they are shown. All day and Calendar are toggles; their
+// text inputs are unused.
+const (
+	fieldTitle formField = iota
+	fieldStart
+	fieldEnd
+	fieldAllDay
+	fieldLocation
+	fieldDescription
+	fieldCalendar
+	fieldCount
+)
+
+var formLabels = [fieldCount]string{"Title", "Start", "End", "All day", "Where", "Notes", "Calendar"}
+
+var (
+	formLabelStyle   = lipgloss.NewStyle().Width(10).Foreground(lipgloss.Color("#5c6370"))
+	formFocusedStyle = lipgloss.NewStyle().Width(10).Foreground(lipgloss.Color("#61afef"))
+	formTitleStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#e5c07b"))
+	formErrStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#e06c75"))
+	formHintStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#5c6370"))
+)
+
+// eventForm edits the fields of a new or existing event.
+type eventForm struct {
+	original  *Event // nil when creating an event
+	inputs    [fieldCount]textinput.Model
+	allDay    bool
+	calendars []string // writable calendars to choose from when creating
+	calendar  int
+	focus     formField
+	err       string
+}
+
+// newEventForm creates a form for an event on day in one of calendars.
+// Today's events start at the next full hour, other days at 9:00.
+func newEventForm(day time.Time, calendars []string) *eventForm {
+	now := time.Now()
+	start := time.Date(day.Year(), day.Month(), day.Day(), 9, 0, 0, 0, time.Local)
+	if sameDay(day, now) {
+		start = now.Truncate(time.Hour).Add(time.Hour)
+	}
+	f := &eventForm{calendars: calendars}
+	f.init(Event{Start: start, End: start.Add(time.Hour)})
+	return f
+}
+
+// editEventForm creates a form filled in with e.
+func editEventForm(e Event) *eventForm {
+	f := &eventForm{original: &e, calendars: []string{e.Calendar}}
+	f.init(e)
+	return f
+}
+
+func (f *eventForm) init(e Event) {
+	for i := range f.inputs {
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.CharLimit = 256
+		f.inputs[i] = ti
+	}
+	f.inputs[fieldTitle].Placeholder = "What"
+	f.inputs[fieldLocation].Placeholder = "optional"
+	f.inputs[fieldDescription].Placeholder = "optional"
+	f.inputs[fieldDescription].CharLimit = 4096
+
+	f.inputs[fieldTitle].SetValue(e.Summary)
+	f.inputs[fieldLocation].SetValue(e.Location)
+	f.inputs[fieldDescription].SetValue(strings.ReplaceAll(e.Description, "\n", " "))
+	f.allDay = e.AllDay
+	f.setTimes(e.Start.Local(), e.End.Local())
+	f.focusField(fieldTitle)
+}
+
+// setTimes fills in the start and end fields. All-day events show the last
+// day instead of the exclusive end.
+func (f *eventForm) setTimes(start, end time.Time) {
+	if f.allDay {
+		last := end.AddDate(0, 0, -1)
+		if last.Before(start) {
+			last = start
+		}
+		f.inputs[fieldStart].SetValue(start.Format(formDateLayout))
+		f.inputs[fieldEnd].SetValue(last.Format(formDateLayout))
+		return
+	}
+	f.inputs[fieldStart].SetValue(start.Format(formDateTimeLayout))
+	f.inputs[fieldEnd].SetValue(end.Format(formDateTimeLayout))
+}
+
+func (f *eventForm) isInput(field formField) bool {
+	return field != fieldAllDay && field != fieldCalendar
+}
+
+func (f *eventForm) focusField(field formField) {
+	f.inputs[f.focus].Blur()
+	f.focus = field
+	if f.isInput(field) {
+		f.inputs[field].Focus()
+	}
+}
+
+// moveFocus moves to the next or previous field, skipping the calendar
+// chooser when there is nothing to choose.
+func (f *eventForm) moveFocus(delta int) {
+	field := f.focus
+	for {
+		field = (field + formField(delta) + fieldCount) % fieldCount
+		if field != fieldCalendar || len(f.calendars) > 1 {
+			break
+		}
+	}
+	f.focusField(field)
+}
+
+// toggleAllDay switches between dates and times, keeping the days.
+func (f *eventForm) toggleAllDay() {
+	start, end, err := f.times()
+	f.allDay = !f.allDay
+	if err != nil {
+		return // leave what the user typed alone
+	}
+	if f.allDay {
+		start = dayStart(start)
+		end = dayStart(end.Add(-time.Nanosecond)).AddDate(0, 0, 1)
+	} else {
+		start = start.Add(9 * time.Hour)
+		end = start.Add(time.Hour)
+	}
+	f.setTimes(start, end)
+}
+
+// Update handles moving between fields and the toggles and passes other
+// messages to the focused text input. Saving and cancelling are left to the
+// calendar.
+func (f *eventForm) Update(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		f.err = ""
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("tab", "down"))):
+			f.moveFocus(1)
+			return textinput.Blink
+		case key.Matches(msg, key.NewBinding(key.WithKeys("shift+tab", "up"))):
+			f.moveFocus(-1)
+			return textinput.Blink
+		}
+		switch f.focus {
+		case fieldAllDay:
+			if key.Matches(msg, key.NewBinding(key.WithKeys(" ", "x"))) {
+				f.toggleAllDay()
+			}
+			return nil
+		case fieldCalendar:
+			switch {
+			case key.Matches(msg, key.NewBinding(key.WithKeys("left", "h"))):
+				f.calendar = (f.calendar - 1 + len(f.calendars)) % len(f.calendars)
+			case key.Matches(msg, key.NewBinding(key.WithKeys("right", "l", " "))):
+				f.calendar = (f.calendar + 1) % len(f.calendars)
+			}
+			return nil
+		}
+	}
+	if !f.isInput(f.focus) {
+		return nil
+	}
+	var cmd tea.Cmd
+	f.inputs[f.focus], cmd = f.inputs[f.focus].Update(msg)
+	return cmd
+}
+
+// times parses the start and end fields. The end may be just a time on the
+// start day.
+func (f *eventForm) times() (time.Time, time.Time, error) {
+	startValue := strings.TrimSpace(f.inputs[fieldStart].Value())
+	endValue := strings.TrimSpace(f.inputs[fieldEnd].Value())
+
+	if f.allDay {
+		start, err := time.ParseInLocation(formDateLayout, firstField(startValue), time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("start must be a date like %s", formDateLayout)
+		}
+		last, err := time.ParseInLocation(formDateLayout, firstField(endValue), time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("end must be a date like %s", formDateLayout)
+		}
+		if last.Before(start) {
+			return time.Time{}, time.Time{}, errors.New("the event ends before it starts")
+		}
+		return start, last.AddDate(0, 0, 1), nil
+	}
+
+	start, err := time.ParseInLocation(formDateTimeLayout, startValue, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("start must look like %s", formDateTimeLayout)
+	}
+	end, err := time.ParseInLocation(formDateTimeLayout, endValue, time.Local)
+	if err != nil {
+		clock, clockErr := time.Parse(formTimeLayout, endValue)
+		if clockErr != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("end must look like %s or %s", formDateTimeLayout, formTimeLayout)
+		}
+		end = time.Date(start.Year(), start.Month(), start.Day(), clock.Hour(), clock.Minute(), 0, 0, time.Local)
+	}
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, errors.New("the event must end after it starts")
+	}
+	return start, end, nil
+}
+
+// event returns the edited event. Fields the form doesn't show are kept from
+// the original event.
+func (f *eventForm) event() (Event, error) {
+	var e Event
+	if f.original != nil {
+		e = *f.original
+	} else {
+		e.Calendar = f.calendars[f.calendar]
+	}
+	e.Summary = strings.TrimSpace(f.inputs[fieldTitle].Value())
+	if e.Summary == "" {
+		return Event{}, errors.New("the event needs a title")
+	}
+	start, end, err := f.times()
+	if err != nil {
+		return Event{}, err
+	}
+	e.Start, e.End, e.AllDay = start, end, f.allDay
+	e.Location = strings.TrimSpace(f.inputs[fieldLocation].Value())
+	description := strings.TrimSpace(f.inputs[fieldDescription].Value())
+	if f.original == nil || description != strings.ReplaceAll(f.original.Description, "\n", " ") {
+		e.Description = description // keep line breaks unless it was edited
+	}
+	return e, nil
+}
+
+func (f *eventForm) View(width int) string {
+	title := "New event"
+	if f.original != nil {
+		title = "Edit event"
+	}
+	lines := []string{formTitleStyle.Render(title)}
+
+	inputWidth := max(8, width-formLabelStyle.GetWidth()-1)
+	for field := fieldTitle; field < fieldCount; field++ {
+		if field == fieldCalendar && len(f.calendars) < 2 {
+			continue
+		}
+		label := formLabelStyle.Render(formLabels[field])
+		if field == f.focus {
+			label = formFocusedStyle.Render(formLabels[field])
+		}
+		var value string
+		switch field {
+		case fieldAllDay:
+			value = "[ ]"
+			if f.allDay {
+				value = "[x]"
+			}
+		case fieldCalendar:
+			value = "‹ " + f.calendars[f.calendar] + " ›"
+		default:
+			f.inputs[field].Width = inputWidth
+			value = f.inputs[field].View()
+		}
+		lines = append(lines, label+value)
+	}
+
+	if f.err != "" {
+		lines = append(lines, formErrStyle.Render(f.err))
+	} else {
+		lines = append(lines, formHintStyle.Render("enter save • esc cancel • tab next"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// firstField returns s up to the first space, so a date and time can be
+// read as a date.
+func firstField(s string) string {
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// dayStart returns local midnight of the day t falls on in local time.
+func dayStart(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// sameDay reports whether a and b fall on the same day in local time.
+func sameDay(a, b time.Time) bool {
+	a, b = a.Local(), b.Local()
+	return a.Year() == b.Year() && a.Month() == b.Month() && a.Day() == b.Day()
+}
@@ -0,0 +1,480 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+
+	"GoDash/internal/config"
+)
+
+// googleProvider reads and edits the calendars of one Google account,
+// the primary calendar unless others are configured.
+type googleProvider struct {
+	name      string
+	account   string
+	calendars []string          // configured calendar names or IDs
+	color     string            // configured color of the primary calendar
+	colors    map[string]string // configured colors by calendar name
+
+	mu       sync.Mutex
+	resolved []GoogleCalendar // calendars, once looked up in the calendar list
+}
+
+// GoogleCalendar is a calendar of a Google account. Name is how it is
+// shown in GoDash, Color the one chosen in Google Calendar.
+type GoogleCalendar struct {
+	Name     string
+	ID       string
+	Color    string
+	Primary  bool
+	ReadOnly bool
+	// DefaultReminders are the minutes before events at which the calendar
+	// reminds by default.
+	DefaultReminders []int
+}
+
+func newGoogleProvider(src config.CalendarSource) *googleProvider {
+	name := src.Name
+	if name == "" {
+		name = "Google"
+	}
+	calendars := src.Calendars
+	if len(calendars) == 0 {
+		calendars = []string{"primary"}
+	}
+	return &googleProvider{
+		name:      name,
+		account:   src.Account,
+		calendars: calendars,
+		color:     src.Color,
+		colors:    src.Colors,
+	}
+}
+
+func (p *googleProvider) Name() string { return p.name }
+
+// calendarNames returns the name of every configured calendar. The primary
+// calendar is named after the provider.
+func (p *googleProvider) calendarNames() []string {
+	names := make([]string, len(p.calendars))
+	for i, c := range p.calendars {
+		names[i] = p.calendarName(c)
+	}
+	return names
+}
+
+func (p *googleProvider) calendarName(entry string) string {
+	if entry == "primary" {
+		return p.name
+	}
+	return entry
+}
+
+// calendarColor returns the configured color of the calendar called name.
+// The calendar's color field applies to the primary calendar.
+func (p *googleProvider) calendarColor(name string) string {
+	if color := p.colors[name]; isHexColor(color) {
+		return color
+	}
+	if name == p.name && isHexColor(p.color) {
+		return p.color
+	}
+	return ""
+}
+
+// writable reports whether the calendar called name may be edited, which
+// is assumed until the calendar list says otherwise.
+func (p *googleProvider) writable(name string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, cal := range p.resolved {
+		if cal.Name == name {
+			return !cal.ReadOnly
+		}
+	}
+	return true
+}
+
+// lookup resolves the configured calendars against the account's calendar
+// list, which also provides their colors. Calendars given by ID don't need
+// the list, so they keep working with tokens that may not read it.
+func (p *googleProvider) lookup(ctx context.Context, srv *calendar.Service) ([]GoogleCalendar, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.resolved != nil {
+		return p.resolved, nil
+	}
+
+	list, listErr := listGoogleCalendars(ctx, srv)
+	var resolved []GoogleCalendar
+	for _, entry := range p.calendars {
+		cal, ok := findGoogleCalendar(list, entry)
+		if !ok {
+			if entry != "primary" && !strings.Contains(entry, "@") {
+				if listErr != nil {
+					return nil, googleListError(listErr)
+				}
+				return nil, fmt.Errorf("no calendar called %q", entry)
+			}
+			cal = GoogleCalendar{ID: entry}
+		}
+		cal.Name = p.calendarName(entry)
+		resolved = append(resolved, cal)
+	}
+	if listErr == nil {
+		p.resolved = resolved
+	}
+	return resolved, nil
+}
+
+// calendarID returns the ID of the calendar called name.
+func (p *googleProvider) calendarID(ctx context.Context, srv *calendar.Service, name string) (string, error) {
+	calendars, err := p.lookup(ctx, srv)
+	if err != nil {
+		return "", err
+	}
+	for _, cal := range calendars {
+		if cal.Name == name {
+			return cal.ID, nil
+		}
+	}
+	return "", fmt.Errorf("no calendar called %q", name)
+}
+
+func (p *googleProvider) Events(ctx context.Context, start, end time.Time) ([]Event, error) {
+	srv, err := GetCalendarService(p.account)
+	if err != nil {
+		return nil, err
+	}
+	calendars, err := p.lookup(ctx, srv)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, cal := range calendars {
+		color := ""
+		if p.calendarColor(cal.Name) == "" {
+			color = cal.Color
+		}
+		err = srv.Events.List(cal.ID).
+			ShowDeleted(false).
+			SingleEvents(true).
+			TimeMin(start.Format(time.RFC3339)).
+			TimeMax(end.Format(time.RFC3339)).
+			OrderBy("startTime").
+			Pages(ctx, func(page *calendar.Events) error {
+				for _, item := range page.Items {
+					if event, ok := eventFromGoogle(item); ok {
+						event.Calendar = cal.Name
+						if event.Color == "" {
+							event.Color = color
+						}
+						// The defaults are for timed events; all-day ones have their own
+						if item.Reminders != nil && item.Reminders.UseDefault && !event.AllDay {
+							event.Reminders = cal.DefaultReminders
+						}
+						events = append(events, event)
+					}
+				}
+				return nil
+			})
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve events of %s: %w", cal.Name, err)
+		}
+	}
+	return events, nil
+}
+
+// changedSince asks each calendar for one event changed since the given
+// time, including deleted ones.
+func (p *googleProvider) changedSince(ctx context.Context, since time.Time) (bool, error) {
+	srv, err := GetCalendarService(p.account)
+	if err != nil {
+		return false, err
+	}
+	calendars, err := p.lookup(ctx, srv)
+	if err != nil {
+		return false, err
+	}
+	for _, cal := range calendars {
+		changed, err := srv.Events.List(cal.ID).
+			UpdatedMin(since.Format(time.RFC3339)).
+			ShowDeleted(true).
+			MaxResults(1).
+			Fields("items(id)").
+			Context(ctx).
+			Do()
+		if err != nil {
+			return false, err
+		}
+		if len(changed.Items) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *googleProvider) CreateEvent(ctx context.Context, e Event) (Event, error) {
+	srv, err := GetCalendarService(p.account)
+	if err != nil {
+		return Event{}, err
+	}
+	id, err := p.calendarID(ctx, srv, e.Calendar)
+	if err != nil {
+		return Event{}, err
+	}
+	item := &calendar.Event{}
+	setGoogleEvent(item, e)
+	created, err := srv.Events.Insert(id, item).Context(ctx).Do()
+	if err != nil {
+		return Event{}, googleWriteError("unable to create event", err)
+	}
+	event, _ := eventFromGoogle(created)
+	return event, nil
+}
+
+// UpdateEvent replaces the edited fields of the event and keeps everything
+// else, such as attendees and reminders, as it is.
+func (p *googleProvider) UpdateEvent(ctx context.Context, e Event) (Event, error) {
+	srv, err := GetCalendarService(p.account)
+	if err != nil {
+		return Event{}, err
+	}
+	id, err := p.calendarID(ctx, srv, e.Calendar)
+	if err != nil {
+		return Event{}, err
+	}
+	item, err := srv.Events.Get(id, e.ID).Context(ctx).Do()
+	if err != nil {
+		return Event{}, googleWriteError("unable to load event", err)
+	}
+	setGoogleEvent(item, e)
+	updated, err := srv.Events.Update(id, e.ID, item).Context(ctx).Do()
+	if err != nil {
+		return Event{}, googleWriteError("unable to update event", err)
+	}
+	event, _ := eventFromGoogle(updated)
+	return event, nil
+}
+
+func (p *googleProvider) DeleteEvent(ctx context.Context, e Event) error {
+	srv, err := GetCalendarService(p.account)
+	if err != nil {
+		return err
+	}
+	id, err := p.calendarID(ctx, srv, e.Calendar)
+	if err != nil {
+		return err
+	}
+	err = srv.Events.Delete(id, e.ID).Context(ctx).Do()
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusGone {
+		return nil // already deleted
+	}
+	if err != nil {
+		return googleWriteError("unable to delete event", err)
+	}
+	return nil
+}
+
+// listGoogleCalendars returns the calendars in the account's calendar list.
+func listGoogleCalendars(ctx context.Context, srv *calendar.Service) ([]GoogleCalendar, error) {
+	var calendars []GoogleCalendar
+	err := srv.CalendarList.List().Pages(ctx, func(page *calendar.CalendarList) error {
+		for _, item := range page.Items {
+			name := item.SummaryOverride
+			if name == "" {
+				name = item.Summary
+			}
+			calendars = append(calendars, GoogleCalendar{
+				Name:     name,
+				ID:       item.Id,
+				Color:    item.BackgroundColor,
+				Primary:  item.Primary,
+				ReadOnly: item.AccessRole != "owner" && item.AccessRole != "writer",
+
+				DefaultReminders: popupMinutes(item.DefaultReminders),
+			})
+		}
+		return nil
+	})
+	return calendars, err
+}
+
+// findGoogleCalendar finds the calendar entry names, by ID or name.
+func findGoogleCalendar(calendars []GoogleCalendar, entry string) (GoogleCalendar, bool) {
+	for _, cal := range calendars {
+		if cal.ID == entry || (entry == "primary" && cal.Primary) {
+			return cal, true
+		}
+	}
+	for _, cal := range calendars {
+		if strings.EqualFold(cal.Name, entry) {
+			return cal, true
+		}
+	}
+	return GoogleCalendar{}, false
+}
+
+// ListGoogleCalendars returns the calendars of a Google account, marking
+// the ones the account may only read.
+func ListGoogleCalendars(ctx context.Context, account string) ([]GoogleCalendar, error) {
+	srv, err := GetCalendarService(account)
+	if err != nil {
+		return nil, err
+	}
+	calendars, err := listGoogleCalendars(ctx, srv)
+	if err != nil {
+		return nil, googleListError(err)
+	}
+	return calendars, nil
+}
+
+// setGoogleEvent copies the fields GoDash edits into item. All-day events
+// use dates with an exclusive end, like Event does.
+func setGoogleEvent(item *calendar.Event, e Event) {
+	item.Summary = e.Summary
+	item.Description = e.Description
+	item.Location = e.Location
+	if e.AllDay {
+		item.Start = &calendar.EventDateTime{Date: e.Start.Format("2006-01-02")}
+		item.End = &calendar.EventDateTime{Date: e.End.Format("2006-01-02")}
+	} else {
+		item.Start = &calendar.EventDateTime{DateTime: e.Start.Format(time.RFC3339)}
+		item.End = &calendar.EventDateTime{DateTime: e.End.Format(time.RFC3339)}
+	}
+	// Send empty fields too so clearing the location or description works.
+	item.ForceSendFields = append(item.ForceSendFields, "Description", "Location")
+}
+
+// googleListError explains failures caused by a token that was granted
+// before GoDash asked to read the calendar list.
+func googleListError(err error) error {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden {
+		return ErrListAccess
+	}
+	return fmt.Errorf("unable to list calendars: %w", err)
+}
+
+// googleWriteError explains failures caused by a token that was granted
+// before GoDash asked for write access.
+func googleWriteError(action string, err error) error {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden &&
+		strings.Contains(strings.ToLower(apiErr.Message), "insufficient") {
+		return ErrWriteAccess
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+// eventFromGoogle converts a Google Calendar API event.
+func eventFromGoogle(item *calendar.Event) (Event, bool) {
+	if item.Start == nil || item.Status == "cancelled" {
+		return Event{}, false
+	}
+	start, allDay, err := googleEventTime(item.Start)
+	if err != nil {
+		return Event{}, false
+	}
+	end := start
+	if item.End != nil {
+		if t, _, err := googleEventTime(item.End); err == nil {
+			end = t
+		}
+	}
+	var reminders []int
+	if item.Reminders != nil && !item.Reminders.UseDefault {
+		reminders = popupMinutes(item.Reminders.Overrides)
+	}
+	return Event{
+		ID:          item.Id,
+		Summary:     item.Summary,
+		Description: item.Description,
+		Location:    item.Location,
+		Start:       start,
+		End:         end,
+		AllDay:      allDay,
+		URL:         item.HtmlLink,
+		Recurring:   item.RecurringEventId != "",
+
+		Attendees:     googleAttendees(item.Attendees),
+		ConferenceURL: googleConferenceURL(item),
+		Color:         googleEventColors[item.ColorId],
+
+		Reminders:   reminders,
+		NoReminders: item.Reminders != nil && !item.Reminders.UseDefault && len(reminders) == 0,
+	}, true
+}
+
+// popupMinutes returns the minutes of the popup reminders; email reminders
+// are sent by Google itself.
+func popupMinutes(reminders []*calendar.EventReminder) []int {
+	var minutes []int
+	for _, r := range reminders {
+		if r.Method == "popup" {
+			minutes = append(minutes, int(r.Minutes))
+		}
+	}
+	return minutes
+}
+
+// googleEventColors are the colors Google Calendar offers for single events,
+// by colorId.
+var googleEventColors = map[string]string{
+	"1": "#7986cb", "2": "#33b679", "3": "#8e24aa", "4": "#e67c73",
+	"5": "#f6bf26", "6": "#f4511e", "7": "#039be5", "8": "#616161",
+	"9": "#3f51b5", "10": "#0b8043", "11": "#d50000",
+}
+
+func googleAttendees(attendees []*calendar.EventAttendee) []Attendee {
+	var list []Attendee
+	for _, a := range attendees {
+		if a.Resource {
+			continue // meeting rooms
+		}
+		list = append(list, Attendee{
+			Name:      a.DisplayName,
+			Email:     a.Email,
+			Status:    a.ResponseStatus,
+			Organizer: a.Organizer,
+			Optional:  a.Optional,
+			Self:      a.Self,
+		})
+	}
+	return list
+}
+
+// googleConferenceURL returns the video entry point of the event's
+// conference, falling back to links in the location or description.
+func googleConferenceURL(item *calendar.Event) string {
+	if item.ConferenceData != nil {
+		for _, ep := range item.ConferenceData.EntryPoints {
+			if ep.EntryPointType == "video" && ep.Uri != "" {
+				return ep.Uri
+			}
+		}
+	}
+	if item.HangoutLink != "" {
+		return item.HangoutLink
+	}
+	return findConferenceURL(item.Location, item.Description)
+}
+
+func googleEventTime(t *calendar.EventDateTime) (time.Time, bool, error) {
+	if t.DateTime != "" {
+		parsed, err := time.Parse(time.RFC3339, t.DateTime)
+		return parsed.Local(), false, err
+	}
+	parsed, err := time.ParseInLocation("2006-01-02", t.Date, time.Local)
+	return parsed, true, err
+}
@@ -0,0 +1,72 @@
+package calendar
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"GoDash/internal/config"
+	"GoDash/internal/holidays"
+)
+
+var (
+	holidayStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#e06c75"))
+	personalStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#c678dd"))
+)
+
+// loadHolidays loads the public holidays of the configured country and the
+// dates file, dates.txt in the config directory unless configured otherwise.
+func loadHolidays(settings *config.HolidaySettings) (*holidays.Calendar, error) {
+	var country, datesFile string
+	if settings != nil {
+		country, datesFile = settings.Country, settings.DatesFile
+	}
+	if datesFile == "" {
+		configDir, err := config.GetConfigDir()
+		if err != nil {
+			return nil, err
+		}
+		datesFile = filepath.Join(configDir, "dates.txt")
+	}
+	return holidays.New(country, datesFile)
+}
+
+// isHoliday reports whether day is a public holiday.
+func (m *Model) isHoliday(day time.Time) bool {
+	for _, h := range m.holidays.On(day) {
+		if h.Kind == holidays.Public {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPersonalDate reports whether a birthday or anniversary falls on day.
+func (m *Model) hasPersonalDate(day time.Time) bool {
+	for _, h := range m.holidays.On(day) {
+		if h.Kind == holidays.Personal {
+			return true
+		}
+	}
+	return false
+}
+
+// holidayLines renders the holidays and personal dates on day, fitting
+// width, to be listed before its events:
+//
+//	★ Independence Day
+//	♥ Maria's birthday (36)
+func (m *Model) holidayLines(day time.Time, width int) []string {
+	var lines []string
+	for _, h := range m.holidays.On(day) {
+		icon, style := "★", holidayStyle
+		if h.Kind == holidays.Personal {
+			icon, style = "♥", personalStyle
+		}
+		lines = append(lines, "  "+style.Render(ansi.Truncate(fmt.Sprintf("%s %s", icon, h.Name), max(0, width-2), "…")))
+	}
+	return lines
+}
@@ -0,0 +1,739 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// icsProperty is a content line of an iCalendar object, e.g.
+// "DTSTART;TZID=Europe/Athens:20240101T090000".
+type icsProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// icsComponent is a BEGIN/END block such as VCALENDAR or VEVENT.
+type icsComponent struct {
+	Name       string
+	Props      []icsProperty
+	Components []*icsComponent
+}
+
+// prop returns the first property called name.
+func (c *icsComponent) prop(name string) (icsProperty, bool) {
+	for _, p := range c.Props {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return icsProperty{}, false
+}
+
+// text returns the unescaped text value of the property called name.
+func (c *icsComponent) text(name string) string {
+	p, ok := c.prop(name)
+	if !ok {
+		return ""
+	}
+	return unescapeICSText(p.Value)
+}
+
+// parseICS parses an iCalendar stream and returns its top level components,
+// normally a single VCALENDAR.
+func parseICS(r io.Reader) ([]*icsComponent, error) {
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []*icsComponent
+	var stack []*icsComponent
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		prop, err := parseICSLine(line)
+		if err != nil {
+			return nil, err
+		}
+		switch prop.Name {
+		case "BEGIN":
+			comp := &icsComponent{Name: strings.ToUpper(prop.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, comp)
+			} else {
+				roots = append(roots, comp)
+			}
+			stack = append(stack, comp)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("unexpected END:%s", prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) > 0 {
+				comp := stack[len(stack)-1]
+				comp.Props = append(comp.Props, prop)
+			}
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1].Name)
+	}
+	return roots, nil
+}
+
+// unfoldICSLines joins continuation lines, which start with a space or tab.
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// parseICSLine splits a content line into name, parameters and value.
+// Parameter values may be quoted and contain ':' or ';'.
+func parseICSLine(line string) (icsProperty, error) {
+	prop := icsProperty{Params: make(map[string]string)}
+
+	i := strings.IndexAny(line, ";:")
+	if i < 0 {
+		return prop, fmt.Errorf("invalid iCalendar line %q", line)
+	}
+	prop.Name = strings.ToUpper(line[:i])
+
+	for line[i] == ';' {
+		rest := line[i+1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq < 0 {
+			return prop, fmt.Errorf("invalid iCalendar parameter in %q", line)
+		}
+		name := strings.ToUpper(rest[:eq])
+		j := eq + 1
+		var value string
+		if j < len(rest) && rest[j] == '"' {
+			end := strings.IndexByte(rest[j+1:], '"')
+			if end < 0 {
+				return prop, fmt.Errorf("unterminated quote in %q", line)
+			}
+			value = rest[j+1 : j+1+end]
+			j += end + 2
+		} else {
+			end := strings.IndexAny(rest[j:], ";:")
+			if end < 0 {
+				return prop, fmt.Errorf("invalid iCalendar line %q", line)
+			}
+			value = rest[j : j+end]
+			j += end
+		}
+		prop.Params[name] = value
+		i += 1 + j
+		if i >= len(line) {
+			return prop, fmt.Errorf("invalid iCalendar line %q", line)
+		}
+	}
+	prop.Value = line[i+1:]
+	return prop, nil
+}
+
+var icsTextReplacer = strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+func unescapeICSText(s string) string {
+	return icsTextReplacer.Replace(s)
+}
+
+// icsTime is a DATE or DATE-TIME value. wall holds the wall clock time as a
+// naive time in UTC; zone maps wall times of the value's timezone to instants,
+// which recurrence expansion needs for every occurrence.
+type icsTime struct {
+	wall   time.Time
+	allDay bool
+	zone   func(wall time.Time) time.Time
+}
+
+func (t icsTime) instant() time.Time { return t.zone(t.wall) }
+
+// key identifies an occurrence for EXDATE and RECURRENCE-ID matching.
+func (t icsTime) key() string {
+	if t.allDay {
+		return t.wall.Format("20060102")
+	}
+	return strconv.FormatInt(t.instant().Unix(), 10)
+}
+
+// icsCalendar resolves the timezones of a VCALENDAR.
+type icsCalendar struct {
+	zones map[string]*vtimezone
+}
+
+func newICSCalendar(root *icsComponent) *icsCalendar {
+	c := &icsCalendar{zones: make(map[string]*vtimezone)}
+	for _, comp := range root.Components {
+		if comp.Name == "VTIMEZONE" {
+			if tz := parseVTimezone(comp); tz != nil {
+				c.zones[tz.id] = tz
+			}
+		}
+	}
+	return c
+}
+
+// localZone interprets wall times in the local timezone, for floating times
+// and dates.
+func localZone(wall time.Time) time.Time {
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, time.Local)
+}
+
+// zone returns the function mapping wall times of tzid to instants. IANA
+// names are preferred; otherwise the VTIMEZONE definition of the calendar is
+// used, as for the Windows zone names Outlook exports. Unknown zones fall
+// back to local time.
+func (c *icsCalendar) zone(tzid string) func(time.Time) time.Time {
+	if tzid == "" {
+		return localZone
+	}
+	if loc := loadICSLocation(tzid); loc != nil {
+		return func(wall time.Time) time.Time {
+			return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+		}
+	}
+	if tz, ok := c.zones[tzid]; ok {
+		return tz.instant
+	}
+	return localZone
+}
+
+// loadICSLocation loads an IANA zone, also accepting prefixed ids such as
+// "/mozilla.org/20050126_1/Europe/Athens".
+func loadICSLocation(tzid string) *time.Location {
+	tzid = strings.Trim(tzid, "/")
+	for {
+		if loc, err := time.LoadLocation(tzid); err == nil && tzid != "" {
+			return loc
+		}
+		i := strings.IndexByte(tzid, '/')
+		if i < 0 {
+			return nil
+		}
+		tzid = tzid[i+1:]
+	}
+}
+
+// parseTime parses a single DATE or DATE-TIME property value.
+func (c *icsCalendar) parseTime(p icsProperty) (icsTime, error) {
+	times, err := c.parseTimes(p)
+	if err != nil {
+		return icsTime{}, err
+	}
+	if len(times) == 0 {
+		return icsTime{}, fmt.Errorf("empty %s", p.Name)
+	}
+	return times[0], nil
+}
+
+// parseTimes parses a property holding a comma separated list of DATE or
+// DATE-TIME values, such as EXDATE or RDATE.
+func (c *icsCalendar) parseTimes(p icsProperty) ([]icsTime, error) {
+	var times []icsTime
+	for _, value := range strings.Split(p.Value, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		var t icsTime
+		var err error
+		switch {
+		case p.Params["VALUE"] == "DATE" || len(value) == 8:
+			t.wall, err = time.ParseInLocation("20060102", value, time.UTC)
+			t.allDay = true
+			t.zone = localZone
+		case strings.HasSuffix(value, "Z"):
+			t.wall, err = time.Parse("20060102T150405Z", value)
+			t.zone = func(wall time.Time) time.Time { return wall }
+		default:
+			t.wall, err = time.ParseInLocation("20060102T150405", value, time.UTC)
+			t.zone = c.zone(p.Params["TZID"])
+		}
+		if err != nil {
+			return nil, err
+		}
+		times = append(times, t)
+	}
+	return times, nil
+}
+
+// event converts a VEVENT component, returning its parsed start as well.
+func (c *icsCalendar) event(comp *icsComponent) (Event, icsTime, bool) {
+	startProp, ok := comp.prop("DTSTART")
+	if !ok {
+		return Event{}, icsTime{}, false
+	}
+	start, err := c.parseTime(startProp)
+	if err != nil {
+		return Event{}, icsTime{}, false
+	}
+	if strings.EqualFold(comp.text("STATUS"), "CANCELLED") {
+		return Event{}, icsTime{}, false
+	}
+
+	startInstant := start.instant()
+	end := startInstant
+	if start.allDay {
+		end = localZone(start.wall.AddDate(0, 0, 1))
+	}
+	if endProp, ok := comp.prop("DTEND"); ok {
+		if t, err := c.parseTime(endProp); err == nil {
+			end = t.instant()
+		}
+	} else if durProp, ok := comp.prop("DURATION"); ok {
+		if d, err := parseICSDuration(durProp.Value); err == nil {
+			end = startInstant.Add(d)
+		}
+	}
+
+	id := comp.text("UID")
+	rid, recurring := comp.prop("RECURRENCE-ID")
+	if recurring {
+		id += "/" + rid.Value
+	}
+	for _, name := range []string{"RRULE", "RDATE"} {
+		if _, ok := comp.prop(name); ok {
+			recurring = true
+		}
+	}
+	return Event{
+		ID:          id,
+		Summary:     comp.text("SUMMARY"),
+		Description: comp.text("DESCRIPTION"),
+		Location:    comp.text("LOCATION"),
+		Start:       startInstant.Local(),
+		End:         end.Local(),
+		AllDay:      start.allDay,
+		URL:         comp.text("URL"),
+		Recurring:   recurring,
+
+		Attendees:     icsAttendees(comp),
+		ConferenceURL: icsConferenceURL(comp),
+		Color:         icsColor(comp.text("COLOR")),
+
+		Reminders: c.reminders(comp, startInstant, end),
+	}, start, true
+}
+
+// reminders returns the minutes before start of the event's VALARMs that
+// show or sound an alarm. Alarms after the start are ignored.
+func (c *icsCalendar) reminders(comp *icsComponent, start, end time.Time) []int {
+	var minutes []int
+	for _, alarm := range comp.Components {
+		if alarm.Name != "VALARM" {
+			continue
+		}
+		if action := strings.ToUpper(alarm.text("ACTION")); action != "DISPLAY" && action != "AUDIO" && action != "" {
+			continue
+		}
+		trigger, ok := alarm.prop("TRIGGER")
+		if !ok {
+			continue
+		}
+		var at time.Time
+		if strings.EqualFold(trigger.Params["VALUE"], "DATE-TIME") {
+			t, err := c.parseTime(trigger)
+			if err != nil {
+				continue
+			}
+			at = t.instant()
+		} else {
+			d, err := parseICSDuration(trigger.Value)
+			if err != nil {
+				continue
+			}
+			at = start.Add(d)
+			if strings.EqualFold(trigger.Params["RELATED"], "END") {
+				at = end.Add(d)
+			}
+		}
+		if before := start.Sub(at); before >= 0 {
+			minutes = append(minutes, int(before/time.Minute))
+		}
+	}
+	return minutes
+}
+
+// icsColor returns the COLOR of RFC 7986 if it is a hex color; CSS color
+// names are ignored.
+func icsColor(value string) string {
+	if isHexColor(value) {
+		return value
+	}
+	return ""
+}
+
+// icsPartStat maps PARTSTAT values to the response statuses of Attendee.
+var icsPartStat = map[string]string{
+	"ACCEPTED":     "accepted",
+	"DECLINED":     "declined",
+	"TENTATIVE":    "tentative",
+	"NEEDS-ACTION": "needsAction",
+}
+
+// icsAttendees reads the ATTENDEE and ORGANIZER properties. The organizer
+// is added to the guests when it isn't one of the attendees already.
+func icsAttendees(comp *icsComponent) []Attendee {
+	var organizer string
+	var attendees []Attendee
+	for _, p := range comp.Props {
+		email := strings.TrimPrefix(strings.TrimPrefix(p.Value, "mailto:"), "MAILTO:")
+		switch p.Name {
+		case "ORGANIZER":
+			organizer = email
+		case "ATTENDEE":
+			if cutype := p.Params["CUTYPE"]; cutype == "ROOM" || cutype == "RESOURCE" {
+				continue
+			}
+			status, ok := icsPartStat[strings.ToUpper(p.Params["PARTSTAT"])]
+			if !ok {
+				status = "needsAction"
+			}
+			attendees = append(attendees, Attendee{
+				Name:     p.Params["CN"],
+				Email:    email,
+				Status:   status,
+				Optional: strings.EqualFold(p.Params["ROLE"], "OPT-PARTICIPANT"),
+			})
+		}
+	}
+	if organizer == "" || len(attendees) == 0 {
+		return attendees
+	}
+	for i := range attendees {
+		if strings.EqualFold(attendees[i].Email, organizer) {
+			attendees[i].Organizer = true
+			return attendees
+		}
+	}
+	prop, _ := comp.prop("ORGANIZER")
+	return append([]Attendee{{Name: prop.Params["CN"], Email: organizer, Status: "accepted", Organizer: true}}, attendees...)
+}
+
+// icsConferenceURL returns the video call link of an event from the
+// CONFERENCE property of RFC 7986, the extensions used by Google and
+// Microsoft, or a link in the location or description.
+func icsConferenceURL(comp *icsComponent) string {
+	for _, name := range []string{"CONFERENCE", "X-GOOGLE-CONFERENCE", "X-MICROSOFT-SKYPETEAMSMEETINGURL"} {
+		if p, ok := comp.prop(name); ok && strings.HasPrefix(p.Value, "http") {
+			return p.Value
+		}
+	}
+	return findConferenceURL(comp.text("LOCATION"), comp.text("DESCRIPTION"))
+}
+
+// events returns the events of a VCALENDAR overlapping [start, end).
+// Recurring events are expanded using RRULE, RDATE and EXDATE, and
+// occurrences modified through RECURRENCE-ID replace the generated ones.
+func (c *icsCalendar) events(root *icsComponent, start, end time.Time) []Event {
+	var masters []*icsComponent
+	overridden := make(map[string]map[string]bool)
+	var events []Event
+
+	for _, comp := range root.Components {
+		if comp.Name != "VEVENT" {
+			continue
+		}
+		rid, ok := comp.prop("RECURRENCE-ID")
+		if !ok {
+			masters = append(masters, comp)
+			continue
+		}
+		if t, err := c.parseTime(rid); err == nil {
+			uid := comp.text("UID")
+			if overridden[uid] == nil {
+				overridden[uid] = make(map[string]bool)
+			}
+			overridden[uid][t.key()] = true
+		}
+		if event, _, ok := c.event(comp); ok && event.overlaps(start, end) {
+			events = append(events, event)
+		}
+	}
+
+	for _, comp := range masters {
+		event, dtstart, ok := c.event(comp)
+		if !ok {
+			continue
+		}
+		ruleProp, hasRule := comp.prop("RRULE")
+		_, hasRDate := comp.prop("RDATE")
+		if !hasRule && !hasRDate {
+			if event.overlaps(start, end) {
+				events = append(events, event)
+			}
+			continue
+		}
+
+		// The first occurrence is DTSTART itself, even without a rule.
+		occurrences := []icsTime{dtstart}
+		if hasRule {
+			if rule, err := parseRRule(ruleProp.Value); err == nil {
+				occurrences = nil
+				for _, wall := range rule.expand(dtstart.wall, dtstart.zone, end) {
+					occurrences = append(occurrences, icsTime{wall: wall, allDay: dtstart.allDay, zone: dtstart.zone})
+				}
+			}
+		}
+		for _, p := range comp.Props {
+			if p.Name == "RDATE" && p.Params["VALUE"] != "PERIOD" {
+				if times, err := c.parseTimes(p); err == nil {
+					occurrences = append(occurrences, times...)
+				}
+			}
+		}
+
+		excluded := make(map[string]bool)
+		for _, p := range comp.Props {
+			if p.Name == "EXDATE" {
+				times, _ := c.parseTimes(p)
+				for _, t := range times {
+					excluded[t.key()] = true
+				}
+			}
+		}
+
+		uid := comp.text("UID")
+		duration := event.End.Sub(event.Start)
+		days := int(duration.Round(24*time.Hour) / (24 * time.Hour))
+		for _, occ := range occurrences {
+			if excluded[occ.key()] || overridden[uid][occ.key()] {
+				continue
+			}
+			instance := event
+			instance.Start = occ.instant().Local()
+			if occ.allDay {
+				instance.End = localZone(occ.wall.AddDate(0, 0, days))
+			} else {
+				instance.End = instance.Start.Add(duration)
+			}
+			instance.ID = uid + "/" + occ.wall.Format("20060102T150405")
+			if instance.overlaps(start, end) {
+				events = append(events, instance)
+			}
+		}
+	}
+	return events
+}
+
+// parseICSDuration parses an RFC 5545 duration such as "PT1H30M" or "P1D".
+func parseICSDuration(s string) (time.Duration, error) {
+	orig := s
+	sign := time.Duration(1)
+	if strings.HasPrefix(s, "-") {
+		sign = -1
+	}
+	s = strings.TrimLeft(s, "+-")
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("invalid duration %q", orig)
+	}
+	s = s[1:]
+
+	var d time.Duration
+	inTime := false
+	num := 0
+	digits := false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			num = num*10 + int(r-'0')
+			digits = true
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+		if !digits {
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+		n := time.Duration(num)
+		switch {
+		case r == 'W':
+			d += n * 7 * 24 * time.Hour
+		case r == 'D':
+			d += n * 24 * time.Hour
+		case r == 'H' && inTime:
+			d += n * time.Hour
+		case r == 'M' && inTime:
+			d += n * time.Minute
+		case r == 'S' && inTime:
+			d += n * time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", orig)
+		}
+		num, digits = 0, false
+	}
+	return sign * d, nil
+}
+
+// eventsFromICSData parses iCalendar data and returns the events overlapping
+// [start, end).
+func eventsFromICSData(r io.Reader, start, end time.Time) ([]Event, error) {
+	roots, err := parseICS(r)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	for _, root := range roots {
+		events = append(events, newICSCalendar(root).events(root, start, end)...)
+	}
+	return events, nil
+}
+
+// --- VTIMEZONE ---
+
+// vtimezone is a timezone defined inside an iCalendar object.
+type vtimezone struct {
+	id          string
+	observances []*tzObservance
+}
+
+// tzObservance is a STANDARD or DAYLIGHT block: from each onset on, wall
+// times have the offset offsetTo.
+type tzObservance struct {
+	start      time.Time // first onset, as a wall time
+	offsetFrom int
+	offsetTo   int
+	rule       *rrule
+	rdates     []time.Time
+
+	// onsets caches the expanded onsets up to onsetsUntil.
+	onsets      []time.Time
+	onsetsUntil time.Time
+}
+
+func parseVTimezone(comp *icsComponent) *vtimezone {
+	tz := &vtimezone{id: comp.text("TZID")}
+	for _, sub := range comp.Components {
+		if sub.Name != "STANDARD" && sub.Name != "DAYLIGHT" {
+			continue
+		}
+		startProp, ok := sub.prop("DTSTART")
+		if !ok {
+			continue
+		}
+		start, err := time.ParseInLocation("20060102T150405", startProp.Value, time.UTC)
+		if err != nil {
+			continue
+		}
+		obs := &tzObservance{start: start}
+		if p, ok := sub.prop("TZOFFSETFROM"); ok {
+			obs.offsetFrom, _ = parseUTCOffset(p.Value)
+		}
+		p, ok := sub.prop("TZOFFSETTO")
+		if !ok {
+			continue
+		}
+		if obs.offsetTo, err = parseUTCOffset(p.Value); err != nil {
+			continue
+		}
+		if p, ok := sub.prop("RRULE"); ok {
+			if rule, err := parseRRule(p.Value); err == nil {
+				obs.rule = &rule
+			}
+		}
+		for _, p := range sub.Props {
+			if p.Name != "RDATE" {
+				continue
+			}
+			for _, v := range strings.Split(p.Value, ",") {
+				if t, err := time.ParseInLocation("20060102T150405", strings.TrimSpace(v), time.UTC); err == nil {
+					obs.rdates = append(obs.rdates, t)
+				}
+			}
+		}
+		tz.observances = append(tz.observances, obs)
+	}
+	if tz.id == "" || len(tz.observances) == 0 {
+		return nil
+	}
+	return tz
+}
+
+// instant maps a wall time to an instant using the observance whose latest
+// onset precedes it.
+func (tz *vtimezone) instant(wall time.Time) time.Time {
+	offset := tz.observances[0].offsetFrom
+	var latest time.Time
+	for _, obs := range tz.observances {
+		onset, ok := obs.lastOnset(wall)
+		if ok && (latest.IsZero() || onset.After(latest)) {
+			latest = onset
+			offset = obs.offsetTo
+		}
+	}
+	loc := time.FixedZone(tz.id, offset)
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+}
+
+// lastOnset returns the latest onset of the observance at or before wall.
+func (obs *tzObservance) lastOnset(wall time.Time) (time.Time, bool) {
+	if obs.start.After(wall) {
+		return time.Time{}, false
+	}
+	if !wall.Before(obs.onsetsUntil) {
+		// Expand well past wall so further lookups are served from the cache.
+		obs.onsetsUntil = time.Date(wall.Year()+50, time.January, 1, 0, 0, 0, 0, time.UTC)
+		obs.onsets = []time.Time{obs.start}
+		if obs.rule != nil {
+			// Onsets are wall times, and so is the limit.
+			identity := func(t time.Time) time.Time { return t }
+			obs.onsets = obs.rule.expand(obs.start, identity, obs.onsetsUntil)
+		}
+		obs.onsets = append(obs.onsets, obs.rdates...)
+		sort.Slice(obs.onsets, func(i, j int) bool { return obs.onsets[i].Before(obs.onsets[j]) })
+	}
+
+	// Index of the first onset after wall.
+	i := sort.Search(len(obs.onsets), func(i int) bool { return obs.onsets[i].After(wall) })
+	if i == 0 {
+		return obs.start, true
+	}
+	return obs.onsets[i-1], true
+}
+
+// parseUTCOffset parses "+0200", "-0500" or "+053000" into seconds.
+func parseUTCOffset(s string) (int, error) {
+	if len(s) != 5 && len(s) != 7 {
+		return 0, fmt.Errorf("invalid UTC offset %q", s)
+	}
+	sign := 1
+	switch s[0] {
+	case '-':
+		sign = -1
+	case '+':
+	default:
+		return 0, fmt.Errorf("invalid UTC offset %q", s)
+	}
+	h, err1 := strconv.Atoi(s[1:3])
+	m, err2 := strconv.Atoi(s[3:5])
+	sec := 0
+	var err3 error
+	if len(s) == 7 {
+		sec, err3 = strconv.Atoi(s[5:7])
+	}
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, fmt.Errorf("invalid UTC offset %q", s)
+	}
+	return sign * (h*3600 + m*60 + sec), nil
+}
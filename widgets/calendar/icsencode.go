@@ -0,0 +1,165 @@
+package calendar
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icsTextEscaper escapes TEXT values, the reverse of icsTextReplacer.
+var icsTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeICSText(s string) string {
+	return icsTextEscaper.Replace(s)
+}
+
+// writeICS serializes a component and its children. Values are written as
+// they are stored, so properties GoDash doesn't understand survive a round
+// trip unchanged.
+func writeICS(w io.Writer, comp *icsComponent) error {
+	if err := writeICSLine(w, "BEGIN:"+comp.Name); err != nil {
+		return err
+	}
+	for _, p := range comp.Props {
+		if err := writeICSLine(w, formatICSProperty(p)); err != nil {
+			return err
+		}
+	}
+	for _, child := range comp.Components {
+		if err := writeICS(w, child); err != nil {
+			return err
+		}
+	}
+	return writeICSLine(w, "END:"+comp.Name)
+}
+
+func formatICSProperty(p icsProperty) string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+	names := make([]string, 0, len(p.Params))
+	for name := range p.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := p.Params[name]
+		if strings.ContainsAny(value, ":;,") {
+			value = `"` + value + `"`
+		}
+		b.WriteString(";" + name + "=" + value)
+	}
+	b.WriteString(":" + p.Value)
+	return b.String()
+}
+
+// writeICSLine folds lines longer than 75 octets without splitting UTF-8
+// sequences.
+func writeICSLine(w io.Writer, line string) error {
+	var b strings.Builder
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74 // the leading space counts
+	}
+	b.WriteString(line + "\r\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// set replaces the properties called name with p, or appends it.
+func (c *icsComponent) set(p icsProperty) {
+	c.remove(p.Name)
+	c.Props = append(c.Props, p)
+}
+
+// setText sets a TEXT property, removing it when value is empty.
+func (c *icsComponent) setText(name, value string) {
+	if value == "" {
+		c.remove(name)
+		return
+	}
+	c.set(icsProperty{Name: name, Value: escapeICSText(value)})
+}
+
+func (c *icsComponent) remove(name string) {
+	props := c.Props[:0]
+	for _, p := range c.Props {
+		if p.Name != name {
+			props = append(props, p)
+		}
+	}
+	c.Props = props
+}
+
+// icsDateTime formats t for DTSTART or DTEND. All-day events use dates;
+// other times keep the timezone the event already had when Go knows it and
+// are written in UTC otherwise.
+func icsDateTime(name string, t time.Time, allDay bool, tzid string) icsProperty {
+	if allDay {
+		return icsProperty{Name: name, Params: map[string]string{"VALUE": "DATE"}, Value: t.Format("20060102")}
+	}
+	if tzid != "" {
+		if loc := loadICSLocation(tzid); loc != nil {
+			return icsProperty{Name: name, Params: map[string]string{"TZID": tzid}, Value: t.In(loc).Format("20060102T150405")}
+		}
+	}
+	return icsProperty{Name: name, Value: t.UTC().Format("20060102T150405Z")}
+}
+
+// setEvent copies the fields GoDash edits into a VEVENT and bumps its
+// sequence number, keeping alarms, attendees and other properties.
+func (c *icsComponent) setEvent(e Event) {
+	var tzid string
+	if p, ok := c.prop("DTSTART"); ok {
+		tzid = p.Params["TZID"]
+	}
+	now := time.Now().UTC().Format("20060102T150405Z")
+
+	c.setText("SUMMARY", e.Summary)
+	c.setText("DESCRIPTION", e.Description)
+	c.setText("LOCATION", e.Location)
+	c.set(icsDateTime("DTSTART", e.Start, e.AllDay, tzid))
+	c.remove("DURATION")
+	c.set(icsDateTime("DTEND", e.End, e.AllDay, tzid))
+	c.set(icsProperty{Name: "DTSTAMP", Value: now})
+	c.set(icsProperty{Name: "LAST-MODIFIED", Value: now})
+	if p, ok := c.prop("SEQUENCE"); ok {
+		seq, _ := strconv.Atoi(p.Value)
+		c.set(icsProperty{Name: "SEQUENCE", Value: strconv.Itoa(seq + 1)})
+	}
+}
+
+// newICSEvent creates a calendar object holding a single new event.
+func newICSEvent(e Event) *icsComponent {
+	vevent := &icsComponent{Name: "VEVENT"}
+	vevent.set(icsProperty{Name: "UID", Value: e.ID})
+	vevent.setEvent(e)
+	vevent.set(icsProperty{Name: "SEQUENCE", Value: "0"})
+	return &icsComponent{
+		Name: "VCALENDAR",
+		Props: []icsProperty{
+			{Name: "VERSION", Value: "2.0"},
+			{Name: "PRODID", Value: "-//GoDash//GoDash//EN"},
+		},
+		Components: []*icsComponent{vevent},
+	}
+}
+
+// newUID returns a random UUID for new events.
+func newUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
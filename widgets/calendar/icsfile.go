@@ -0,0 +1,134 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"GoDash/internal/config"
+)
+
+// icsFileProvider reads events from local .ics files and directories, such
+// as exported calendars or vdir folders synced by vdirsyncer, where every
+// event is a separate .ics file.
+type icsFileProvider struct {
+	name  string
+	paths []string
+}
+
+func newICSFileProvider(src config.CalendarSource) (*icsFileProvider, error) {
+	if len(src.Paths) == 0 {
+		return nil, fmt.Errorf("ics calendar %q needs at least one path", src.Name)
+	}
+	var paths []string
+	for _, path := range src.Paths {
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, rest)
+			}
+		}
+		paths = append(paths, path)
+	}
+	name := src.Name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(paths[0]), ".ics")
+	}
+	return &icsFileProvider{name: name, paths: paths}, nil
+}
+
+func (p *icsFileProvider) Name() string { return p.name }
+
+// Events parses the files on every call so edits made by other programs or
+// a sync show up on the next fetch.
+func (p *icsFileProvider) Events(ctx context.Context, start, end time.Time) ([]Event, error) {
+	files, err := p.files()
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := eventsFromICSData(f, start, end)
+		f.Close()
+		if err != nil {
+			continue // a single malformed item shouldn't hide the calendar
+		}
+		events = append(events, parsed...)
+	}
+	return events, nil
+}
+
+// changedSince reports whether a file or folder was modified since the
+// given time. Folders change when files are added or removed.
+func (p *icsFileProvider) changedSince(ctx context.Context, since time.Time) (bool, error) {
+	changed := false
+	for _, path := range p.paths {
+		err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && file != path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if info.ModTime().After(since) {
+				changed = true
+				return filepath.SkipAll
+			}
+			return ctx.Err()
+		})
+		if err != nil || changed {
+			return changed, err
+		}
+	}
+	return false, nil
+}
+
+// files lists the .ics files of all configured paths. Directories are
+// searched recursively, skipping hidden folders.
+func (p *icsFileProvider) files() ([]string, error) {
+	var files []string
+	for _, path := range p.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if file != path && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.EqualFold(filepath.Ext(d.Name()), ".ics") {
+				files = append(files, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,273 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"GoDash/internal/config"
+)
+
+// Event is a calendar event independent of the provider it came from. Times
+// are in the local timezone; for all-day events Start is midnight of the first
+// day and End is midnight after the last day.
+type Event struct {
+	ID          string    `json:"id"`
+	Calendar    string    `json:"calendar,omitempty"` // name of the calendar
+	Summary     string    `json:"summary"`
+	Description string    `json:"description,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	AllDay      bool      `json:"all_day,omitempty"`
+	URL         string    `json:"url,omitempty"`       // web page of the event, if any
+	Recurring   bool      `json:"recurring,omitempty"` // occurrence of a recurring event
+	Href        string    `json:"href,omitempty"`      // CalDAV object the event is stored in
+
+	Attendees     []Attendee `json:"attendees,omitempty"`
+	ConferenceURL string     `json:"conference_url,omitempty"` // video call link, if any
+	Color         string     `json:"color,omitempty"`          // overrides the calendar's color
+
+	// Reminders are the minutes before the start at which the event asks to
+	// be announced. Events without any use the default lead time, unless
+	// NoReminders is set because they were turned off.
+	Reminders   []int `json:"reminders,omitempty"`
+	NoReminders bool  `json:"no_reminders,omitempty"`
+}
+
+// Attendee is a guest of an event. Status is one of the Google Calendar
+// response statuses: "accepted", "declined", "tentative" or "needsAction".
+type Attendee struct {
+	Name      string `json:"name,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Organizer bool   `json:"organizer,omitempty"`
+	Optional  bool   `json:"optional,omitempty"`
+	Self      bool   `json:"self,omitempty"` // the signed in user
+}
+
+// Provider is a source of calendar events such as Google Calendar or a CalDAV
+// server.
+type Provider interface {
+	// Name identifies the calendar in the UI.
+	Name() string
+	// Events returns the events overlapping [start, end).
+	Events(ctx context.Context, start, end time.Time) ([]Event, error)
+}
+
+// EventWriter is implemented by providers whose events can be created,
+// changed and deleted from GoDash. The returned events carry the IDs assigned
+// by the provider.
+type EventWriter interface {
+	CreateEvent(ctx context.Context, e Event) (Event, error)
+	UpdateEvent(ctx context.Context, e Event) (Event, error)
+	DeleteEvent(ctx context.Context, e Event) error
+}
+
+// calendarSet is implemented by providers holding several calendars, such
+// as a Google account. Their events name the calendar they belong to.
+type calendarSet interface {
+	calendarNames() []string
+	// calendarColor returns the configured color of a calendar, if any.
+	calendarColor(name string) string
+	// writable reports whether a calendar may be edited.
+	writable(name string) bool
+}
+
+// defaultSources is used when no calendars are configured.
+var defaultSources = []config.CalendarSource{{Type: "google", Name: "Google"}}
+
+// NewProviders creates the providers configured in sources.
+func NewProviders(sources []config.CalendarSource) ([]Provider, error) {
+	if len(sources) == 0 {
+		sources = defaultSources
+	}
+	var providers []Provider
+	for _, src := range sources {
+		switch src.Type {
+		case "google", "":
+			providers = append(providers, newGoogleProvider(src))
+		case "caldav":
+			p, err := newCalDAVProvider(src)
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, p)
+		case "ics":
+			p, err := newICSFileProvider(src)
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, p)
+		default:
+			return nil, fmt.Errorf("unknown calendar type %q", src.Type)
+		}
+	}
+	return providers, nil
+}
+
+// calendarPalette colors the calendars that don't configure a color.
+var calendarPalette = []string{"#61afef", "#98c379", "#e5c07b", "#c678dd", "#56b6c2", "#e06c75", "#d19a66"}
+
+// calendarColors maps the name of every calendar to its color, taken from
+// sources or the palette. Providers are created in the order of sources.
+func calendarColors(sources []config.CalendarSource, providers []Provider) map[string]string {
+	if len(sources) == 0 {
+		sources = defaultSources
+	}
+	colors := make(map[string]string)
+	next := 0
+	paletteColor := func() string {
+		color := calendarPalette[next%len(calendarPalette)]
+		next++
+		return color
+	}
+	for i, p := range providers {
+		if set, ok := p.(calendarSet); ok {
+			for _, name := range set.calendarNames() {
+				color := paletteColor()
+				if c := set.calendarColor(name); c != "" {
+					color = c
+				}
+				colors[name] = color
+			}
+			continue
+		}
+		color := paletteColor()
+		if i < len(sources) && isHexColor(sources[i].Color) {
+			color = sources[i].Color
+		}
+		colors[p.Name()] = color
+	}
+	return colors
+}
+
+// isHexColor reports whether s is a color like "#abc" or "#a1b2c3".
+func isHexColor(s string) bool {
+	if (len(s) != 4 && len(s) != 7) || s[0] != '#' {
+		return false
+	}
+	for _, c := range s[1:] {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// GoogleAccounts returns the Google accounts used by sources, the default
+// account being "".
+func GoogleAccounts(sources []config.CalendarSource) []string {
+	if len(sources) == 0 {
+		sources = defaultSources
+	}
+	var accounts []string
+	seen := make(map[string]bool)
+	for _, src := range sources {
+		if (src.Type == "google" || src.Type == "") && !seen[src.Account] {
+			seen[src.Account] = true
+			accounts = append(accounts, src.Account)
+		}
+	}
+	return accounts
+}
+
+// UnauthorizedAccount returns the first Google account used by sources that
+// GoDash has not been authorized to access yet.
+func UnauthorizedAccount(sources []config.CalendarSource) (string, bool) {
+	for _, account := range GoogleAccounts(sources) {
+		if !IsAuthorized(account) {
+			return account, true
+		}
+	}
+	return "", false
+}
+
+// fetchEvents collects the events of all providers in [start, end), sorted by
+// start time. Providers that fail are left out; an error is only returned
+// when every provider failed, preferring ErrAuthRequired so the app can ask
+// for authorization, or when the sign-in of an account expired.
+func fetchEvents(ctx context.Context, providers []Provider, start, end time.Time) ([]Event, error) {
+	var all []Event
+	var errs []error
+	for _, p := range providers {
+		events, err := p.Events(ctx, start, end)
+		if err != nil {
+			var expired *AuthExpiredError
+			if errors.As(err, &expired) {
+				return nil, expired
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+			continue
+		}
+		for i := range events {
+			if events[i].Calendar == "" {
+				events[i].Calendar = p.Name()
+			}
+		}
+		all = append(all, events...)
+	}
+	if len(errs) > 0 && len(errs) == len(providers) {
+		for _, err := range errs {
+			if errors.Is(err, ErrAuthRequired) {
+				return nil, ErrAuthRequired
+			}
+		}
+		return nil, errors.Join(errs...)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Start.Before(all[j].Start) })
+	return all, nil
+}
+
+// overlaps reports whether e overlaps [start, end).
+func (e Event) overlaps(start, end time.Time) bool {
+	eventEnd := e.End
+	if !eventEnd.After(e.Start) {
+		eventEnd = e.Start.Add(time.Nanosecond) // zero length events
+	}
+	return e.Start.Before(end) && eventEnd.After(start)
+}
+
+// writers returns the names of the calendars that can be edited.
+func writers(providers []Provider) []string {
+	var names []string
+	for _, p := range providers {
+		if _, ok := p.(EventWriter); !ok {
+			continue
+		}
+		if set, ok := p.(calendarSet); ok {
+			for _, name := range set.calendarNames() {
+				if set.writable(name) {
+					names = append(names, name)
+				}
+			}
+			continue
+		}
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+// writerFor returns the provider of the calendar called name if the
+// calendar can be edited.
+func writerFor(providers []Provider, name string) (EventWriter, bool) {
+	for _, p := range providers {
+		if set, ok := p.(calendarSet); ok {
+			for _, n := range set.calendarNames() {
+				if n == name {
+					w, ok := p.(EventWriter)
+					return w, ok && set.writable(name)
+				}
+			}
+			continue
+		}
+		if p.Name() == name {
+			w, ok := p.(EventWriter)
+			return w, ok
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,398 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"GoDash/internal/config"
+	"GoDash/internal/notify"
+)
+
+const (
+	// reminderInterval is how often the cached events are checked for due
+	// reminders.
+	reminderInterval = 30 * time.Second
+	// reminderGrace is how long after an event started a reminder of it is
+	// still shown, so those due at the start aren't missed between checks.
+	reminderGrace = time.Minute
+
+	defaultLeadMinutes   = 10
+	defaultSnoozeMinutes = 5
+)
+
+// reminders announces events shortly before they start, in a banner and
+// optionally as desktop notifications.
+type reminders struct {
+	disabled bool
+	lead     time.Duration // for events without reminders of their own
+	snooze   time.Duration
+	notifier *notify.Notifier
+
+	active  []reminder       // shown, the first one in the banner
+	snoozed []reminder       // shown again at their due time
+	fired   map[string]fired // by occurrence
+}
+
+// fired is the latest reminder announced for an event occurrence.
+type fired struct {
+	at    time.Time
+	start time.Time
+}
+
+// reminder is a due reminder of an event occurrence.
+type reminder struct {
+	key          string
+	event        Event
+	due          time.Time
+	notification uint32 // desktop notification ID, 0 if none
+}
+
+func newReminders(settings *config.ReminderSettings) reminders {
+	r := reminders{
+		lead:   defaultLeadMinutes * time.Minute,
+		snooze: defaultSnoozeMinutes * time.Minute,
+		fired:  make(map[string]fired),
+	}
+	if settings == nil {
+		return r
+	}
+	r.disabled = settings.Disabled
+	if settings.LeadMinutes > 0 {
+		r.lead = time.Duration(settings.LeadMinutes) * time.Minute
+	}
+	if settings.SnoozeMinutes > 0 {
+		r.snooze = time.Duration(settings.SnoozeMinutes) * time.Minute
+	}
+	if settings.Desktop && !r.disabled {
+		n, err := notify.Open()
+		if err != nil {
+			fmt.Printf("Desktop notifications are unavailable: %v\n", err)
+		} else {
+			r.notifier = n
+		}
+	}
+	return r
+}
+
+// reminderTickMsg checks for due reminders.
+type reminderTickMsg time.Time
+
+func reminderTick() tea.Cmd {
+	return tea.Tick(reminderInterval, func(t time.Time) tea.Msg {
+		return reminderTickMsg(t)
+	})
+}
+
+// notificationSentMsg carries the ID of the desktop notification of a
+// reminder.
+type notificationSentMsg struct {
+	key string
+	id  uint32
+}
+
+// notificationActionMsg reports a click on an action of a desktop
+// notification.
+type notificationActionMsg notify.Invoked
+
+// initCmd starts checking for reminders and listening to notifications.
+func (r *reminders) initCmd(now time.Time) tea.Cmd {
+	if r.disabled {
+		return nil
+	}
+	return tea.Batch(
+		func() tea.Msg { return reminderTickMsg(now) },
+		r.listen(),
+	)
+}
+
+// listen waits for the next click on a notification action.
+func (r *reminders) listen() tea.Cmd {
+	if r.notifier == nil {
+		return nil
+	}
+	invoked := r.notifier.Invoked()
+	return func() tea.Msg {
+		return notificationActionMsg(<-invoked)
+	}
+}
+
+// occurrenceKey identifies an occurrence of an event, so a moved event is
+// announced again at its new time.
+func occurrenceKey(e Event) string {
+	return e.Calendar + "\x00" + e.ID + "\x00" + e.Start.Format(time.RFC3339)
+}
+
+// triggers returns when e asks to be announced, latest first.
+func (r *reminders) triggers(e Event) []time.Time {
+	if e.NoReminders {
+		return nil
+	}
+	var leads []time.Duration
+	for _, minutes := range e.Reminders {
+		leads = append(leads, time.Duration(minutes)*time.Minute)
+	}
+	// All-day events start at midnight; a default lead would announce them
+	// in the middle of the night.
+	if len(leads) == 0 && !e.AllDay {
+		leads = append(leads, r.lead)
+	}
+	sort.Slice(leads, func(i, j int) bool { return leads[i] < leads[j] })
+	var times []time.Time
+	for _, lead := range leads {
+		times = append(times, e.Start.Add(-lead))
+	}
+	return times
+}
+
+// checkReminders announces the reminders due at now among the cached events of this
+// month and the next, and returns the commands sending their notifications.
+func (m *Model) checkReminders(now time.Time) tea.Cmd {
+	r := &m.reminders
+	var cmds []tea.Cmd
+
+	// Forget reminders of events that are over.
+	r.active = keepReminders(r.active, now, func(rem reminder) { cmds = append(cmds, r.close(rem)) })
+	r.snoozed = keepReminders(r.snoozed, now, nil)
+	for key, f := range r.fired {
+		if now.Sub(f.start) > reminderGrace {
+			delete(r.fired, key)
+		}
+	}
+
+	// Bring back snoozed reminders.
+	var still []reminder
+	for _, rem := range r.snoozed {
+		if rem.due.After(now) {
+			still = append(still, rem)
+			continue
+		}
+		cmds = append(cmds, r.activate(rem))
+	}
+	r.snoozed = still
+
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	for _, monthKey := range []string{month.Format("2006-01"), month.AddDate(0, 1, 0).Format("2006-01")} {
+		for _, e := range m.cachedEvents[monthKey] {
+			if now.Sub(e.Start) > reminderGrace {
+				continue
+			}
+			key := occurrenceKey(e)
+			for _, at := range r.triggers(e) {
+				if at.After(now) {
+					continue
+				}
+				if f, ok := r.fired[key]; ok && !at.After(f.at) {
+					break
+				}
+				r.fired[key] = fired{at: at, start: e.Start}
+				cmds = append(cmds, r.activate(reminder{key: key, event: e, due: at}))
+				break
+			}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// keepReminders drops the reminders of events that ended, calling drop for
+// each.
+func keepReminders(list []reminder, now time.Time, drop func(reminder)) []reminder {
+	var kept []reminder
+	for _, rem := range list {
+		end := rem.event.End
+		if end.IsZero() {
+			end = rem.event.Start
+		}
+		if !end.After(now) && now.Sub(rem.event.Start) > reminderGrace {
+			if drop != nil {
+				drop(rem)
+			}
+			continue
+		}
+		kept = append(kept, rem)
+	}
+	return kept
+}
+
+// activate shows rem, replacing an earlier reminder of the same occurrence.
+func (r *reminders) activate(rem reminder) tea.Cmd {
+	for i, old := range r.active {
+		if old.key == rem.key {
+			rem.notification = old.notification
+			r.active = append(r.active[:i], r.active[i+1:]...)
+			break
+		}
+	}
+	r.active = append(r.active, rem)
+	sort.SliceStable(r.active, func(i, j int) bool {
+		return r.active[i].event.Start.Before(r.active[j].event.Start)
+	})
+	return r.notify(rem)
+}
+
+// notify sends the desktop notification of rem.
+func (r *reminders) notify(rem reminder) tea.Cmd {
+	if r.notifier == nil {
+		return nil
+	}
+	n := r.notifier
+	summary := rem.event.Summary
+	if summary == "" {
+		summary = "(No title)"
+	}
+	body := reminderWhen(rem.event, time.Now())
+	if rem.event.Location != "" {
+		body += "\n" + rem.event.Location
+	}
+	actions := []notify.Action{
+		{Key: "snooze", Label: fmt.Sprintf("Snooze %d min", int(r.snooze.Minutes()))},
+		{Key: "dismiss", Label: "Dismiss"},
+	}
+	return func() tea.Msg {
+		id, err := n.Send(summary, body, actions, rem.notification)
+		if err != nil {
+			return nil
+		}
+		return notificationSentMsg{key: rem.key, id: id}
+	}
+}
+
+// close removes the desktop notification of rem.
+func (r *reminders) close(rem reminder) tea.Cmd {
+	if r.notifier == nil || rem.notification == 0 {
+		return nil
+	}
+	n := r.notifier
+	return func() tea.Msg {
+		n.Close(rem.notification)
+		return nil
+	}
+}
+
+// take removes the active reminder at i and returns it.
+func (r *reminders) take(i int) reminder {
+	rem := r.active[i]
+	r.active = append(r.active[:i], r.active[i+1:]...)
+	return rem
+}
+
+func (r *reminders) snoozeAt(i int, now time.Time) tea.Cmd {
+	rem := r.take(i)
+	rem.due = now.Add(r.snooze)
+	cmd := r.close(rem)
+	rem.notification = 0
+	r.snoozed = append(r.snoozed, rem)
+	return cmd
+}
+
+func (r *reminders) dismissAt(i int) tea.Cmd {
+	return r.close(r.take(i))
+}
+
+// updateReminders handles the reminder messages.
+func (m *Model) updateReminders(msg tea.Msg) tea.Cmd {
+	r := &m.reminders
+	switch msg := msg.(type) {
+	case reminderTickMsg:
+		return tea.Batch(reminderTick(), m.checkReminders(time.Time(msg)))
+	case notificationSentMsg:
+		for i := range r.active {
+			if r.active[i].key == msg.key {
+				r.active[i].notification = msg.id
+				return nil
+			}
+		}
+		// Dismissed or snoozed before the notification was shown.
+		return r.close(reminder{notification: msg.id})
+	case notificationActionMsg:
+		for i, rem := range r.active {
+			if rem.notification != msg.ID {
+				continue
+			}
+			switch msg.Key {
+			case "snooze":
+				return tea.Batch(r.listen(), r.snoozeAt(i, time.Now()))
+			case "dismiss":
+				return tea.Batch(r.listen(), r.dismissAt(i))
+			}
+			break
+		}
+		return r.listen()
+	}
+	return nil
+}
+
+// HasReminder reports whether a reminder is being shown.
+func (m *Model) HasReminder() bool {
+	return len(m.reminders.active) > 0
+}
+
+// ReminderText describes the first reminder being shown, if any.
+func (m *Model) ReminderText() string {
+	if len(m.reminders.active) == 0 {
+		return ""
+	}
+	e := m.reminders.active[0].event
+	summary := e.Summary
+	if summary == "" {
+		summary = "(No title)"
+	}
+	parts := []string{summary + " " + reminderWhen(e, time.Now())}
+	if e.Location != "" {
+		parts = append(parts, e.Location)
+	}
+	if more := len(m.reminders.active) - 1; more > 0 {
+		parts = append(parts, fmt.Sprintf("+%d more", more))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// SnoozeReminder hides the reminder in the banner for the snooze time.
+func (m *Model) SnoozeReminder() tea.Cmd {
+	if len(m.reminders.active) == 0 {
+		return nil
+	}
+	return m.reminders.snoozeAt(0, time.Now())
+}
+
+// DismissReminder hides the reminder in the banner for good.
+func (m *Model) DismissReminder() tea.Cmd {
+	if len(m.reminders.active) == 0 {
+		return nil
+	}
+	return m.reminders.dismissAt(0)
+}
+
+// reminderWhen says when e starts, relative to now.
+func reminderWhen(e Event, now time.Time) string {
+	if e.AllDay {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		start := time.Date(e.Start.Year(), e.Start.Month(), e.Start.Day(), 0, 0, 0, 0, now.Location())
+		switch days := int(start.Sub(today).Hours() / 24); {
+		case days <= 0:
+			return "today"
+		case days == 1:
+			return "tomorrow"
+		default:
+			return start.Format("on Mon, Jan 2")
+		}
+	}
+	until := e.Start.Sub(now).Round(time.Minute)
+	switch {
+	case until <= 0:
+		return "now (" + e.Start.Format("15:04") + ")"
+	case until < time.Hour:
+		return fmt.Sprintf("in %d min (%s)", int(until.Minutes()), e.Start.Format("15:04"))
+	case until < 24*time.Hour:
+		hours, minutes := int(until.Hours()), int(until.Minutes())%60
+		if minutes == 0 {
+			return fmt.Sprintf("in %d h (%s)", hours, e.Start.Format("15:04"))
+		}
+		return fmt.Sprintf("in %d h %d min (%s)", hours, minutes, e.Start.Format("15:04"))
+	default:
+		return e.Start.Format("on Mon, Jan 2 at 15:04")
+	}
+}
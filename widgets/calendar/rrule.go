@@ -0,0 +1,351 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rrule is a parsed RFC 5545 recurrence rule. Occurrences are computed on
+// naive wall clock times (stored in UTC) so daylight saving transitions don't
+// shift them; the caller maps them to instants in the event's timezone.
+type rrule struct {
+	freq       string
+	interval   int
+	count      int
+	until      time.Time // instant, zero if unbounded
+	untilDate  bool      // UNTIL was a DATE, compared by day
+	byDay      []weekdayNum
+	byMonthDay []int
+	byMonth    []int
+	bySetPos   []int
+	wkst       time.Weekday
+}
+
+// weekdayNum is a BYDAY entry such as "MO" (n = 0), "2TU" or "-1FR".
+type weekdayNum struct {
+	n   int
+	day time.Weekday
+}
+
+var icsWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// maxRecurrencePeriods bounds the expansion of rules that never match.
+const maxRecurrencePeriods = 100000
+
+func parseRRule(value string) (rrule, error) {
+	r := rrule{interval: 1, wkst: time.Monday}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.freq = strings.ToUpper(val)
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(val)
+			if err == nil && r.interval < 1 {
+				err = fmt.Errorf("invalid INTERVAL %q", val)
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(val)
+		case "UNTIL":
+			if len(val) == 8 {
+				r.until, err = time.ParseInLocation("20060102", val, time.UTC)
+				r.untilDate = true
+			} else if strings.HasSuffix(val, "Z") {
+				r.until, err = time.Parse("20060102T150405Z", val)
+			} else {
+				r.until, err = time.ParseInLocation("20060102T150405", val, time.Local)
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				d = strings.ToUpper(strings.TrimSpace(d))
+				if len(d) < 2 {
+					return r, fmt.Errorf("invalid BYDAY %q", val)
+				}
+				day, ok := icsWeekdays[d[len(d)-2:]]
+				if !ok {
+					return r, fmt.Errorf("invalid BYDAY %q", val)
+				}
+				n := 0
+				if prefix := d[:len(d)-2]; prefix != "" {
+					if n, err = strconv.Atoi(prefix); err != nil {
+						return r, fmt.Errorf("invalid BYDAY %q", val)
+					}
+				}
+				r.byDay = append(r.byDay, weekdayNum{n: n, day: day})
+			}
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseIntList(val)
+		case "BYMONTH":
+			r.byMonth, err = parseIntList(val)
+		case "BYSETPOS":
+			r.bySetPos, err = parseIntList(val)
+		case "WKST":
+			if day, ok := icsWeekdays[strings.ToUpper(val)]; ok {
+				r.wkst = day
+			}
+		}
+		if err != nil {
+			return r, fmt.Errorf("invalid RRULE %q: %v", value, err)
+		}
+	}
+	switch r.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return r, fmt.Errorf("unsupported RRULE frequency %q", r.freq)
+	}
+	return r, nil
+}
+
+func parseIntList(s string) ([]int, error) {
+	var list []int
+	for _, v := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, n)
+	}
+	return list, nil
+}
+
+// expand returns the occurrences of the rule for an event starting at the
+// wall time dtstart, up to the first occurrence whose instant (as computed by
+// toInstant) is at or after limit.
+func (r rrule) expand(dtstart time.Time, toInstant func(time.Time) time.Time, limit time.Time) []time.Time {
+	var occurrences []time.Time
+	count := 0
+	for period := 0; period < maxRecurrencePeriods; period++ {
+		candidates := r.periodCandidates(dtstart, period)
+		if len(r.bySetPos) > 0 {
+			candidates = applySetPos(candidates, r.bySetPos)
+		}
+		for _, c := range candidates {
+			if c.Before(dtstart) {
+				continue
+			}
+			instant := toInstant(c)
+			if !r.until.IsZero() {
+				if r.untilDate && c.Truncate(24*time.Hour).After(r.until) {
+					return occurrences
+				}
+				if !r.untilDate && instant.After(r.until) {
+					return occurrences
+				}
+			}
+			if !instant.Before(limit) {
+				return occurrences
+			}
+			occurrences = append(occurrences, c)
+			count++
+			if r.count > 0 && count >= r.count {
+				return occurrences
+			}
+		}
+	}
+	return occurrences
+}
+
+// periodCandidates returns the sorted candidate occurrences of the n-th
+// period (day, week, month or year) after the one containing dtstart.
+func (r rrule) periodCandidates(dtstart time.Time, n int) []time.Time {
+	h, m, s := dtstart.Clock()
+	at := func(y int, mon time.Month, d int) time.Time {
+		return time.Date(y, mon, d, h, m, s, 0, time.UTC)
+	}
+	step := n * r.interval
+
+	var candidates []time.Time
+	switch r.freq {
+	case "DAILY":
+		day := dtstart.AddDate(0, 0, step)
+		if r.matchesMonth(day.Month()) && r.matchesMonthDay(day) && r.matchesWeekday(day) {
+			candidates = append(candidates, day)
+		}
+	case "WEEKLY":
+		// Align to the start of the week per WKST.
+		offset := (int(dtstart.Weekday()) - int(r.wkst) + 7) % 7
+		weekStart := dtstart.AddDate(0, 0, -offset+7*step)
+		for i := 0; i < 7; i++ {
+			day := weekStart.AddDate(0, 0, i)
+			if len(r.byDay) == 0 && day.Weekday() != dtstart.Weekday() {
+				continue
+			}
+			if r.matchesWeekday(day) && r.matchesMonth(day.Month()) {
+				candidates = append(candidates, day)
+			}
+		}
+	case "MONTHLY":
+		first := time.Date(dtstart.Year(), dtstart.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, step, 0)
+		if r.matchesMonth(first.Month()) {
+			for _, d := range r.monthDays(first.Year(), first.Month(), dtstart.Day()) {
+				candidates = append(candidates, at(first.Year(), first.Month(), d))
+			}
+		}
+	case "YEARLY":
+		year := dtstart.Year() + step
+		switch {
+		case len(r.byMonth) > 0:
+			for _, mon := range r.byMonth {
+				for _, d := range r.monthDays(year, time.Month(mon), dtstart.Day()) {
+					candidates = append(candidates, at(year, time.Month(mon), d))
+				}
+			}
+		case len(r.byDay) > 0 && len(r.byMonthDay) == 0:
+			// Ordinals count within the whole year, e.g. 20MO.
+			for _, yd := range yearWeekdays(year, r.byDay) {
+				candidates = append(candidates, at(year, time.January, yd))
+			}
+		default:
+			for _, d := range r.monthDays(year, dtstart.Month(), dtstart.Day()) {
+				candidates = append(candidates, at(year, dtstart.Month(), d))
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+	return candidates
+}
+
+// monthDays returns the days of a month selected by BYMONTHDAY and BYDAY, or
+// defaultDay when neither is set. Days that don't exist are skipped.
+func (r rrule) monthDays(year int, month time.Month, defaultDay int) []int {
+	daysIn := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	var fromMonthDay []int
+	for _, d := range r.byMonthDay {
+		if d < 0 {
+			d = daysIn + d + 1
+		}
+		if d >= 1 && d <= daysIn {
+			fromMonthDay = append(fromMonthDay, d)
+		}
+	}
+
+	var fromWeekday []int
+	for _, wd := range r.byDay {
+		var matches []int
+		for d := 1; d <= daysIn; d++ {
+			if time.Date(year, month, d, 0, 0, 0, 0, time.UTC).Weekday() == wd.day {
+				matches = append(matches, d)
+			}
+		}
+		switch {
+		case wd.n == 0:
+			fromWeekday = append(fromWeekday, matches...)
+		case wd.n > 0 && wd.n <= len(matches):
+			fromWeekday = append(fromWeekday, matches[wd.n-1])
+		case wd.n < 0 && -wd.n <= len(matches):
+			fromWeekday = append(fromWeekday, matches[len(matches)+wd.n])
+		}
+	}
+
+	switch {
+	case len(r.byMonthDay) > 0 && len(r.byDay) > 0:
+		var both []int
+		for _, d := range fromMonthDay {
+			for _, w := range fromWeekday {
+				if d == w {
+					both = append(both, d)
+					break
+				}
+			}
+		}
+		return both
+	case len(r.byMonthDay) > 0:
+		return fromMonthDay
+	case len(r.byDay) > 0:
+		return fromWeekday
+	case defaultDay <= daysIn:
+		return []int{defaultDay}
+	}
+	return nil
+}
+
+// yearWeekdays returns the days of the year (1-based, usable as January
+// days) selected by BYDAY entries whose ordinals count within the year.
+func yearWeekdays(year int, byDay []weekdayNum) []int {
+	daysIn := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	var days []int
+	for _, wd := range byDay {
+		var matches []int
+		for d := 1; d <= daysIn; d++ {
+			if time.Date(year, time.January, d, 0, 0, 0, 0, time.UTC).Weekday() == wd.day {
+				matches = append(matches, d)
+			}
+		}
+		switch {
+		case wd.n == 0:
+			days = append(days, matches...)
+		case wd.n > 0 && wd.n <= len(matches):
+			days = append(days, matches[wd.n-1])
+		case wd.n < 0 && -wd.n <= len(matches):
+			days = append(days, matches[len(matches)+wd.n])
+		}
+	}
+	return days
+}
+
+func (r rrule) matchesMonth(m time.Month) bool {
+	if len(r.byMonth) == 0 {
+		return true
+	}
+	for _, bm := range r.byMonth {
+		if time.Month(bm) == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (r rrule) matchesMonthDay(t time.Time) bool {
+	if len(r.byMonthDay) == 0 {
+		return true
+	}
+	daysIn := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, d := range r.byMonthDay {
+		if d == t.Day() || (d < 0 && daysIn+d+1 == t.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday checks BYDAY for DAILY and WEEKLY rules, where ordinals
+// don't apply.
+func (r rrule) matchesWeekday(t time.Time) bool {
+	if len(r.byDay) == 0 {
+		return true
+	}
+	for _, wd := range r.byDay {
+		if wd.day == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// applySetPos keeps the candidates at the BYSETPOS positions of a period.
+func applySetPos(candidates []time.Time, positions []int) []time.Time {
+	var selected []time.Time
+	for _, pos := range positions {
+		i := pos - 1
+		if pos < 0 {
+			i = len(candidates) + pos
+		}
+		if i >= 0 && i < len(candidates) {
+			selected = append(selected, candidates[i])
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Before(selected[j]) })
+	return selected
+}
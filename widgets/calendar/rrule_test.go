@@ -0,0 +1,154 @@
+package calendar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRRuleExpand(t *testing.T) {
+	// Thursday, January 1st 2026 at 09:00.
+	dtstart := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		rule  string
+		limit time.Time
+		want  []string
+	}{
+		{
+			name: "daily count",
+			rule: "FREQ=DAILY;COUNT=3",
+			want: []string{"2026-01-01", "2026-01-02", "2026-01-03"},
+		},
+		{
+			name: "daily interval until",
+			rule: "FREQ=DAILY;INTERVAL=2;UNTIL=20260107T090000Z",
+			want: []string{"2026-01-01", "2026-01-03", "2026-01-05", "2026-01-07"},
+		},
+		{
+			name: "until date includes the last day",
+			rule: "FREQ=DAILY;UNTIL=20260103",
+			want: []string{"2026-01-01", "2026-01-02", "2026-01-03"},
+		},
+		{
+			name: "until before a time of day",
+			rule: "FREQ=DAILY;UNTIL=20260103T080000Z",
+			want: []string{"2026-01-01", "2026-01-02"},
+		},
+		{
+			name: "weekly byday",
+			rule: "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=5",
+			want: []string{"2026-01-01", "2026-01-05", "2026-01-08", "2026-01-12", "2026-01-15"},
+		},
+		{
+			name: "biweekly byday until",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU;UNTIL=20260210T235959Z",
+			want: []string{"2026-01-13", "2026-01-27", "2026-02-10"},
+		},
+		{
+			name: "monthly nth weekday",
+			rule: "FREQ=MONTHLY;BYDAY=2TU;COUNT=3",
+			want: []string{"2026-01-13", "2026-02-10", "2026-03-10"},
+		},
+		{
+			name: "monthly last friday",
+			rule: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			want: []string{"2026-01-30", "2026-02-27", "2026-03-27"},
+		},
+		{
+			name: "last weekday of the month",
+			rule: "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;COUNT=3",
+			want: []string{"2026-01-30", "2026-02-27", "2026-03-31"},
+		},
+		{
+			name: "monthly on the 31st skips short months",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=3",
+			want: []string{"2026-01-31", "2026-03-31", "2026-05-31"},
+		},
+		{
+			name: "yearly bymonth byday",
+			rule: "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH;COUNT=2",
+			want: []string{"2026-11-26", "2027-11-25"},
+		},
+		{
+			name:  "stops at the limit",
+			rule:  "FREQ=WEEKLY",
+			limit: time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC),
+			want:  []string{"2026-01-01", "2026-01-08", "2026-01-15"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := parseRRule(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			end := tt.limit
+			if end.IsZero() {
+				end = dtstart.AddDate(3, 0, 0)
+			}
+			var got []string
+			for _, occ := range rule.expand(dtstart, func(t time.Time) time.Time { return t }, end) {
+				got = append(got, occ.Format("2006-01-02"))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expand(%s) = %v, want %v", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRRuleErrors(t *testing.T) {
+	for _, rule := range []string{
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYDAY=ATU",
+		"FREQ=DAILY;UNTIL=tomorrow",
+	} {
+		if _, err := parseRRule(rule); err == nil {
+			t.Errorf("parseRRule(%q) succeeded, want an error", rule)
+		}
+	}
+}
+
+func TestICSRecurrenceExceptions(t *testing.T) {
+	data := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:gym",
+		"DTSTART:20260105T180000Z",
+		"DTEND:20260105T190000Z",
+		"SUMMARY:Gym",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=6",
+		"EXDATE:20260107T180000Z,20260114T180000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:gym",
+		"RECURRENCE-ID:20260112T180000Z",
+		"DTSTART:20260112T200000Z",
+		"DTEND:20260112T210000Z",
+		"SUMMARY:Gym (late)",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	events, err := eventsFromICSData(strings.NewReader(data), start, start.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Start.UTC().Format("2006-01-02 15:04")+" "+e.Summary)
+	}
+	want := []string{
+		"2026-01-12 20:00 Gym (late)",
+		"2026-01-05 18:00 Gym",
+		"2026-01-19 18:00 Gym",
+		"2026-01-21 18:00 Gym",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+}
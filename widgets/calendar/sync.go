@@ -0,0 +1,176 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// refreshInterval is how often the shown months are checked for changes,
+// and how old a month may get before it is.
+const refreshInterval = 5 * time.Minute
+
+// changeDetector is implemented by providers that can tell cheaply whether
+// any of their events changed since a time, so months they haven't changed
+// are kept without fetching them again.
+type changeDetector interface {
+	changedSince(ctx context.Context, since time.Time) (bool, error)
+}
+
+// refreshTickMsg starts a background refresh.
+type refreshTickMsg time.Time
+
+func refreshTick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg {
+		return refreshTickMsg(t)
+	})
+}
+
+// refreshedMsg carries a cached month fetched again. fetched is the time the
+// refresh started, or the previous one if a provider failed and kept its
+// cached events.
+type refreshedMsg struct {
+	monthKey string
+	events   []Event
+	fetched  time.Time
+	manual   bool
+	err      error
+}
+
+// IsBackgroundMsg reports whether msg belongs to the calendar's background
+// work, such as fetching, saving and refreshing events and reminding of
+// them, which has to reach the calendar whatever the app is showing.
+func IsBackgroundMsg(msg tea.Msg) bool {
+	switch msg.(type) {
+	case EventsMsg, EventsErrMsg, eventWrittenMsg, refreshTickMsg, refreshedMsg,
+		reminderTickMsg, notificationSentMsg, notificationActionMsg:
+		return true
+	}
+	return false
+}
+
+// refreshMonth fetches a cached month again. Unless manual is set, providers
+// that can tell nothing changed since fetched keep their cached events.
+func refreshMonth(providers []Provider, month time.Time, cached []Event, fetched time.Time, manual bool) tea.Cmd {
+	monthKey := month.Format("2006-01")
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0)
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		started := time.Now()
+
+		var events []Event
+		var errs []error
+		for _, p := range providers {
+			own := providerEvents(p, cached)
+			if d, ok := p.(changeDetector); ok && !manual && !fetched.IsZero() {
+				if changed, err := d.changedSince(ctx, fetched); err == nil && !changed {
+					events = append(events, own...)
+					continue
+				}
+			}
+			fresh, err := p.Events(ctx, start, end)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+				events = append(events, own...)
+				continue
+			}
+			for i := range fresh {
+				if fresh[i].Calendar == "" {
+					fresh[i].Calendar = p.Name()
+				}
+			}
+			events = append(events, fresh...)
+		}
+		sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+
+		if len(errs) > 0 {
+			started = fetched
+		}
+		return refreshedMsg{monthKey: monthKey, events: events, fetched: started, manual: manual, err: errors.Join(errs...)}
+	}
+}
+
+// providerEvents returns the events of events that belong to p.
+func providerEvents(p Provider, events []Event) []Event {
+	names := map[string]bool{p.Name(): true}
+	if set, ok := p.(calendarSet); ok {
+		names = make(map[string]bool)
+		for _, name := range set.calendarNames() {
+			names[name] = true
+		}
+	}
+	var own []Event
+	for _, e := range events {
+		if names[e.Calendar] {
+			own = append(own, e)
+		}
+	}
+	return own
+}
+
+// refreshMonths refreshes the cached months shown by the current view and
+// the current month. Background refreshes skip the months fetched less than
+// refreshInterval ago; manual ones fetch everything again and also load the
+// months that aren't cached yet.
+func (m *Model) refreshMonths(manual bool) tea.Cmd {
+	start, end := m.shownDays()
+	months := []time.Time{time.Now()}
+	month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.Local)
+	for ; month.Before(end); month = month.AddDate(0, 1, 0) {
+		months = append(months, month)
+	}
+
+	var cmds []tea.Cmd
+	for _, month := range months {
+		monthKey := month.Format("2006-01")
+		cached, ok := m.cachedEvents[monthKey]
+		if !ok || m.fetchingMonths[monthKey] {
+			continue
+		}
+		fetched := m.fetchedAt[monthKey]
+		if !manual && time.Since(fetched) < refreshInterval {
+			continue
+		}
+		m.fetchingMonths[monthKey] = true
+		cmds = append(cmds, refreshMonth(m.providers, month, cached, fetched, manual))
+	}
+	if manual {
+		cmds = append(cmds, m.fetchShownMonths())
+	}
+	return tea.Batch(cmds...)
+}
+
+// finishRefresh stores a refreshed month, keeping the selected event
+// selected. Results are dropped while changes are being saved, as they may
+// not include them yet; the month is refreshed again on the next tick.
+func (m *Model) finishRefresh(msg refreshedMsg) {
+	m.fetchingMonths[msg.monthKey] = false
+	if m.pendingWrites > 0 {
+		if msg.manual {
+			m.setStatus("Changes are still being saved, try again in a moment", false)
+		}
+		return
+	}
+
+	selected, hasSelection := m.SelectedEvent()
+	m.cachedEvents[msg.monthKey] = msg.events
+	m.fetchedAt[msg.monthKey] = msg.fetched
+	m.afterCacheChange()
+	if hasSelection {
+		m.selectEvent(selected)
+	}
+
+	if msg.manual {
+		if msg.err != nil {
+			m.setStatus(fmt.Sprintf("Couldn't refresh: %v", msg.err), true)
+		} else {
+			m.setStatus("Calendar refreshed", false)
+		}
+	}
+}
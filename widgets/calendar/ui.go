@@ -2,6 +2,8 @@
 package calendar
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -11,8 +13,9 @@ import (
 	"github.com/ethanefung/bubble-datepicker"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"google.golang.org/api/calendar/v3"
 
+	"GoDash/internal/config"
+	"GoDash/internal/holidays"
 	"GoDash/widgets/clock"
 	"GoDash/widgets/weather"
 )
@@ -46,6 +49,11 @@ func fetchWeather(city string) tea.Cmd {
 	}
 }
 
+var (
+	statusStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("#98c379"))
+	statusErrStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#e06c75"))
+)
+
 type calendarState int
 
 const (
@@ -58,12 +66,23 @@ const fetchCoolDown = 5 * time.Second
 type Model struct {
 	state          calendarState
 	DatePicker     datepicker.Model
-	events         []*calendar.Event
+	providers      []Provider
+	colors         map[string]string // calendar name to color
+	events         []Event
+	selected       int // index in events of the selected event
 	selectedDate   time.Time
-	cachedEvents   map[string][]*calendar.Event
+	view           calendarView
+	agendaStart    time.Time // first day listed by the agenda view
+	cachedEvents   map[string][]Event
+	fetchedAt      map[string]time.Time // when each cached month was fetched
 	fetchingMonths map[string]bool
 	lastFetchTime  time.Time
 	err            error
+	form           *eventForm
+	confirmDelete  bool
+	pendingWrites  int // changes sent to providers but not confirmed yet
+	status         string // result of the last change
+	statusErr      bool
 	loading        bool
 	spinner        spinner.Model
 	keys           KeyMap
@@ -72,14 +91,24 @@ type Model struct {
 	weatherErr     error
 	weatherLoading bool
 	location       string
+	reminders      reminders
+	holidays       *holidays.Calendar
 	width, height  int
 }
 
 type KeyMap struct {
-	Confirm key.Binding
+	Confirm     key.Binding
+	Cancel      key.Binding
+	AddEvent    key.Binding
+	EditEvent   key.Binding
+	DeleteEvent key.Binding
+	NextEvent   key.Binding
+	PrevEvent   key.Binding
+	SwitchView  key.Binding
+	Refresh     key.Binding
 }
 
-func New(keys KeyMap, location string) Model {
+func New(keys KeyMap, location string, sources []config.CalendarSource, reminderSettings *config.ReminderSettings, holidaySettings *config.HolidaySettings) Model {
 	dp := datepicker.New(time.Now())
 	dpStyles := datepicker.DefaultStyles()
 	dpStyles.SelectedText = lipgloss.NewStyle().Foreground(lipgloss.Color("#61afef"))
@@ -92,33 +121,49 @@ func New(keys KeyMap, location string) Model {
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 
-	cachedEvents, err := LoadCalendarCache()
+	cachedEvents, fetchedAt, err := LoadCalendarCache()
 	if err != nil {
 		// Log the error but continue with an empty cache
 		fmt.Printf("Error loading calendar cache: %v. Starting fresh.\n", err)
-		cachedEvents = make(map[string][]*calendar.Event)
+		cachedEvents = make(map[string][]Event)
+		fetchedAt = make(map[string]time.Time)
+	}
+
+	providers, providersErr := NewProviders(sources)
+
+	holidayCalendar, err := loadHolidays(holidaySettings)
+	if err != nil {
+		fmt.Printf("Error loading holidays: %v\n", err)
 	}
 
 	return Model{
 		state:          StateIdle,
+		providers:      providers,
+		colors:         calendarColors(sources, providers),
+		err:            providersErr,
 		DatePicker:     dp,
 		selectedDate:   time.Now(),
 		cachedEvents:   cachedEvents,
+		fetchedAt:      fetchedAt,
 		fetchingMonths: make(map[string]bool),
 		spinner:        s,
 		keys:           keys,
 		clock:          clock.New(),
 		weatherLoading: true,
 		location:       location,
+		reminders:      newReminders(reminderSettings),
+		holidays:       holidayCalendar,
 	}
 }
 
 
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
-		FetchEventsForMonth(time.Now()),
+		FetchEventsForMonth(m.providers, time.Now()),
 		m.clock.Init(),
 		fetchWeather(m.location),
+		refreshTick(),
+		m.reminders.initCmd(time.Now()),
 	)
 }
 
@@ -129,24 +174,35 @@ func (m *Model) Update(msg tea.Msg, focused bool) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case EventsMsg:
 		m.cachedEvents[msg.MonthKey] = msg.Events
+		m.fetchedAt[msg.MonthKey] = msg.Fetched
 		m.fetchingMonths[msg.MonthKey] = false
 		m.loading = false
 		m.state = StateReady
 		m.filterEventsForSelectedDate()
 		// Save the updated cache to disk in a non-blocking way
-		go SaveCalendarCache(m.cachedEvents)
+		m.saveCache()
 		return *m, nil
 	case EventsErrMsg:
 		m.fetchingMonths[msg.MonthKey] = false
-		if msg.Err == ErrAuthRequired {
+		if cmd := authRequired(msg.Err); cmd != nil {
 			m.state = StateIdle
 			m.loading = false
 			// Let the main model handle auth.
-			return *m, nil
+			return *m, cmd
 		}
 		m.err = msg.Err
 		m.loading = false
 		return *m, nil
+	case eventWrittenMsg:
+		m.finishWrite(msg)
+		return *m, authRequired(msg.err)
+	case refreshTickMsg:
+		return *m, tea.Batch(refreshTick(), m.refreshMonths(false))
+	case refreshedMsg:
+		m.finishRefresh(msg)
+		return *m, authRequired(msg.err)
+	case reminderTickMsg, notificationSentMsg, notificationActionMsg:
+		return *m, m.updateReminders(msg)
 	case weatherMsg:
 		m.weather = msg.w
 		m.weatherLoading = false
@@ -165,9 +221,38 @@ func (m *Model) Update(msg tea.Msg, focused bool) (Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
+	if focused && m.form != nil {
+		cmds = append(cmds, m.updateForm(msg))
+		return *m, tea.Batch(cmds...)
+	}
+	if focused && m.confirmDelete {
+		cmds = append(cmds, m.updateConfirmDelete(msg))
+		return *m, tea.Batch(cmds...)
+	}
+
 	if focused {
 		switch m.state {
 		case StateReady:
+			if keyMsg, ok := msg.(tea.KeyMsg); ok {
+				m.status = ""
+				if cmd, handled := m.updateEditing(keyMsg); handled {
+					cmds = append(cmds, cmd)
+					return *m, tea.Batch(cmds...)
+				}
+				if key.Matches(keyMsg, m.keys.Refresh) {
+					m.setStatus("Refreshing…", false)
+					cmds = append(cmds, m.refreshMonths(true))
+					return *m, tea.Batch(cmds...)
+				}
+				if cmd, handled := m.updateView(keyMsg); handled {
+					cmds = append(cmds, cmd)
+					return *m, tea.Batch(cmds...)
+				}
+				if m.view != viewMonth {
+					// The datepicker isn't shown, so leave the date alone.
+					return *m, tea.Batch(cmds...)
+				}
+			}
 			var datepickerCmd tea.Cmd
 			m.DatePicker.SetFocus(datepicker.FocusCalendar)
 			m.DatePicker.SelectDate()
@@ -187,7 +272,7 @@ func (m *Model) Update(msg tea.Msg, focused bool) (Model, tea.Cmd) {
 					m.loading = true
 					m.fetchingMonths[monthKey] = true
 					m.lastFetchTime = time.Now()
-					datepickerCmd = tea.Batch(datepickerCmd, FetchEventsForMonth(m.selectedDate))
+					datepickerCmd = tea.Batch(datepickerCmd, FetchEventsForMonth(m.providers, m.selectedDate))
 				}
 				// If it's already being fetched, do nothing, the spinner is already on.
 			}
@@ -206,32 +291,51 @@ func (m *Model) View() string {
 
 	// Left side: Calendar and events
 	var leftSide string
-	if m.loading {
+	leftWidth, rightWidth := m.columnWidths()
+	if m.form != nil {
+		leftSide = m.form.View(leftWidth - 1)
+	} else if m.loading {
 		leftSide = m.spinner.View()
+	} else if m.view == viewWeek {
+		// The week needs the clock and weather column's room too.
+		week := m.weekView(m.width, m.height)
+		if m.status != "" {
+			week += "\n" + m.statusView(m.width)
+		}
+		return lipgloss.NewStyle().Width(m.width).Height(m.height).Render(week)
+	} else if m.view == viewAgenda {
+		leftSide = m.agendaView(leftWidth-1, m.height)
+		if m.status != "" {
+			leftSide += "\n" + m.statusView(leftWidth-1)
+		}
 	} else {
 		var eventsTodayBuilder strings.Builder
-		if len(m.events) > 0 {
-			datePickerHeight := 8
-			availableLines := m.height - datePickerHeight
-
-			if availableLines > 0 {
-				numToShow := min(len(m.events), availableLines)
-
-				maxSummaryLength := max(0, m.width-14)
-
-				for i := range numToShow {
-					summary := m.events[i].Summary
-					summary = strings.ReplaceAll(summary, "\n", " ")
-					if len(summary) > maxSummaryLength {
-						summary = summary[:maxSummaryLength]
-					}
-					redText := lipgloss.NewStyle().Foreground(lipgloss.Color("#be8a59"))
-					eventsTodayBuilder.WriteString(redText.Render( summary) + "\n")
-				}
+		datePickerHeight := 8
+		availableLines := m.height - datePickerHeight
+		if m.status != "" {
+			availableLines--
+		}
+		for _, line := range m.holidayLines(m.selectedDate, leftWidth-1) {
+			if availableLines <= 0 {
+				break
+			}
+			eventsTodayBuilder.WriteString(line + "\n")
+			availableLines--
+		}
+		if len(m.events) > 0 && availableLines > 0 {
+			numToShow := min(len(m.events), availableLines)
+			// Scroll so the selected event stays visible.
+			first := max(0, m.selected-numToShow+1)
+
+			now := time.Now()
+			for i := first; i < first+numToShow; i++ {
+				line := m.agendaLine(m.events[i], m.selectedDate, leftWidth-1, i == m.selected, now)
+				eventsTodayBuilder.WriteString(line + "\n")
 			}
 		}
+		eventsTodayBuilder.WriteString(m.statusView(leftWidth - 1))
 		eventsToday := strings.TrimSuffix(eventsTodayBuilder.String(), "\n")
-		leftSide = lipgloss.JoinVertical(lipgloss.Left, m.DatePicker.View(), eventsToday)
+		leftSide = lipgloss.JoinVertical(lipgloss.Left, m.datepickerView(), eventsToday)
 	}
 
 	// Right side: Clock and Weather
@@ -279,31 +383,6 @@ func (m *Model) View() string {
 		return lipgloss.NewStyle().Width(m.width).Height(m.height).Render(leftSide)
 	}
 
-	// Calculate responsive widths
-	minLeftWidth := 30  // Minimum width for calendar
-	minRightWidth := 20 // Minimum width for clock/weather
-	
-	// For smaller screens, use fixed proportions
-	var leftWidth, rightWidth int
-	if m.width < 80 {
-		leftWidth = minLeftWidth
-		rightWidth = m.width - leftWidth - 2
-	} else {
-		// For larger screens, use more balanced proportions
-		leftWidth = min(m.width*2/3, 40) // Max 50 chars for left side
-		rightWidth = m.width - leftWidth - 2
-	}
-	
-	// Ensure minimum widths
-	if leftWidth < minLeftWidth {
-		leftWidth = minLeftWidth
-		rightWidth = m.width - leftWidth - 2
-	}
-	if rightWidth < minRightWidth {
-		rightWidth = minRightWidth
-		leftWidth = m.width - rightWidth - 2
-	}
-
 	leftSideWithBorder := lipgloss.NewStyle().
 		Width(leftWidth).
 		Border(lipgloss.NormalBorder(), false, true, false, false).
@@ -335,6 +414,52 @@ func (m *Model) View() string {
 	return lipgloss.JoinHorizontal(lipgloss.Top, leftSideWithBorder, rightSide)
 }
 
+// statusView renders the result of the last change, if any.
+func (m *Model) statusView(width int) string {
+	if m.status == "" {
+		return ""
+	}
+	style := statusStyle
+	if m.statusErr {
+		style = statusErrStyle
+	}
+	return style.Width(width).Render(m.status)
+}
+
+// columnWidths returns the widths of the calendar column and of the clock
+// and weather column, which is hidden on narrow screens.
+func (m *Model) columnWidths() (int, int) {
+	if m.width < 45 {
+		return m.width, 0
+	}
+
+	// Calculate responsive widths
+	minLeftWidth := 30  // Minimum width for calendar
+	minRightWidth := 20 // Minimum width for clock/weather
+	
+	// For smaller screens, use fixed proportions
+	var leftWidth, rightWidth int
+	if m.width < 80 {
+		leftWidth = minLeftWidth
+		rightWidth = m.width - leftWidth - 2
+	} else {
+		// For larger screens, use more balanced proportions
+		leftWidth = min(m.width*2/3, 40) // Max 50 chars for left side
+		rightWidth = m.width - leftWidth - 2
+	}
+	
+	// Ensure minimum widths
+	if leftWidth < minLeftWidth {
+		leftWidth = minLeftWidth
+		rightWidth = m.width - leftWidth - 2
+	}
+	if rightWidth < minRightWidth {
+		rightWidth = minRightWidth
+		leftWidth = m.width - rightWidth - 2
+	}
+	return leftWidth, rightWidth
+}
+
 func (m *Model) SetSize(width, height int) {
 	m.width = width
 	m.height = height
@@ -345,32 +470,8 @@ func (m *Model) State() calendarState {
 }
 
 func (m *Model) filterEventsForSelectedDate() {
-	monthKey := m.selectedDate.Format("2006-01")
-	if monthlyEvents, ok := m.cachedEvents[monthKey]; ok {
-		var dailyEvents []*calendar.Event
-		for _, event := range monthlyEvents {
-			var eventDate time.Time
-			var err error
-			if event.Start.DateTime != "" {
-				eventDate, err = time.Parse(time.RFC3339, event.Start.DateTime)
-			} else {
-				eventDate, err = time.Parse("2006-01-02", event.Start.Date)
-			}
-
-			if err != nil {
-				continue // Or handle error
-			}
-
-			if eventDate.Day() == m.selectedDate.Day() &&
-				eventDate.Month() == m.selectedDate.Month() &&
-				eventDate.Year() == m.selectedDate.Year() {
-				dailyEvents = append(dailyEvents, event)
-			}
-		}
-		m.events = dailyEvents
-	} else {
-		m.events = nil // No events for this month in cache
-	}
+	m.events = m.eventsOn(m.selectedDate)
+	m.selected = max(0, min(m.selected, len(m.events)-1))
 }
 
 // --- Messages ---
@@ -378,31 +479,75 @@ func (m *Model) filterEventsForSelectedDate() {
 // EventsMsg represents a message containing calendar events for a specific month.
 type EventsMsg struct {
 	MonthKey string
-	Events   []*calendar.Event
+	Events   []Event
+	Fetched  time.Time // when the fetch started
 }
 type EventsErrMsg struct {
 	MonthKey string
 	Err      error
 }
 
+// AuthRequiredMsg asks the app to authorize a Google account again because
+// GoDash is no longer signed in to it. Err explains why if the sign-in
+// expired or was revoked.
+type AuthRequiredMsg struct {
+	Err error
+}
+
+// authRequired returns a command sending AuthRequiredMsg if err shows that
+// an account has to be authorized again.
+func authRequired(err error) tea.Cmd {
+	var expired *AuthExpiredError
+	switch {
+	case errors.As(err, &expired):
+		return func() tea.Msg { return AuthRequiredMsg{Err: expired} }
+	case errors.Is(err, ErrAuthRequired):
+		return func() tea.Msg { return AuthRequiredMsg{} }
+	}
+	return nil
+}
+
+
+// DisableGoogle stops fetching the Google calendars, for when GoDash can't
+// or shouldn't sign in to them, and shows reason in the panel.
+func (m *Model) DisableGoogle(reason error) {
+	var providers []Provider
+	for _, p := range m.providers {
+		if _, ok := p.(*googleProvider); !ok {
+			providers = append(providers, p)
+		}
+	}
+	m.providers = providers
+	if len(providers) == 0 {
+		m.err = reason
+	} else {
+		m.setStatus(reason.Error(), true)
+	}
+}
+
+// ShowError reports err in the panel's status line.
+func (m *Model) ShowError(err error) {
+	m.setStatus(err.Error(), true)
+}
 
 // --- Commands ---
 
-// FetchEventsForMonth creates a command to fetch calendar events for the specified month.
-func FetchEventsForMonth(month time.Time) tea.Cmd {
+// FetchEventsForMonth creates a command to fetch the events of all providers
+// for the specified month.
+func FetchEventsForMonth(providers []Provider, month time.Time) tea.Cmd {
 	monthKey := month.Format("2006-01")
+	firstDayOfMonth := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	firstDayOfNextMonth := firstDayOfMonth.AddDate(0, 1, 0)
 	return func() tea.Msg {
-		srv, err := GetCalendarService()
-		if err != nil {
-			return EventsErrMsg{MonthKey: monthKey, Err: err}
-		}
-		events, err := GetCalendarEventsForMonth(srv, month)
+		fetched := time.Now()
+		events, err := fetchEvents(context.Background(), providers, firstDayOfMonth, firstDayOfNextMonth)
 		if err != nil {
 			return EventsErrMsg{MonthKey: monthKey, Err: err}
 		}
 		return EventsMsg{
 			MonthKey: monthKey,
 			Events:   events,
+			Fetched:  fetched,
 		}
 	}
 }
@@ -0,0 +1,482 @@
+package calendar
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/ethanefung/bubble-datepicker"
+)
+
+// calendarView is the layout of the calendar panel.
+type calendarView int
+
+const (
+	viewMonth  calendarView = iota // datepicker and the selected day's events
+	viewWeek                       // seven day columns of hour slots
+	viewAgenda                     // the events of the next agendaDays days
+)
+
+// agendaDays is the number of days listed by the agenda view.
+const agendaDays = 14
+
+// weekGutterWidth is the width of the hour labels in the week view.
+const weekGutterWidth = 3
+
+var (
+	viewTitleStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#e5c07b"))
+	dayHeaderStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#abb2bf")).Bold(true)
+	dayTodayStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#e5c07b")).Bold(true)
+	daySelectedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#61afef")).Bold(true)
+	weekGridStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#4b5263"))
+	weekSelectedStyle = lipgloss.NewStyle().Bold(true).Underline(true)
+)
+
+var (
+	prevDayKey  = key.NewBinding(key.WithKeys("left", "h"))
+	nextDayKey  = key.NewBinding(key.WithKeys("right", "l"))
+	prevWeekKey = key.NewBinding(key.WithKeys("up", "k"))
+	nextWeekKey = key.NewBinding(key.WithKeys("down", "j"))
+)
+
+// updateView switches views and handles the date keys of the week and agenda
+// views, which replace the datepicker's. It reports whether msg was used.
+func (m *Model) updateView(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if key.Matches(msg, m.keys.SwitchView) {
+		m.view = (m.view + 1) % 3
+		m.agendaStart = dayStart(m.selectedDate)
+		return m.fetchShownMonths(), true
+	}
+
+	switch m.view {
+	case viewWeek:
+		switch {
+		case key.Matches(msg, prevDayKey):
+			return m.selectDate(m.selectedDate.AddDate(0, 0, -1)), true
+		case key.Matches(msg, nextDayKey):
+			return m.selectDate(m.selectedDate.AddDate(0, 0, 1)), true
+		case key.Matches(msg, prevWeekKey):
+			return m.selectDate(m.selectedDate.AddDate(0, 0, -7)), true
+		case key.Matches(msg, nextWeekKey):
+			return m.selectDate(m.selectedDate.AddDate(0, 0, 7)), true
+		}
+	case viewAgenda:
+		switch {
+		case key.Matches(msg, prevDayKey):
+			cmd := m.selectDate(m.selectedDate.AddDate(0, 0, -1))
+			m.agendaStart = dayStart(m.selectedDate)
+			return cmd, true
+		case key.Matches(msg, nextDayKey):
+			cmd := m.selectDate(m.selectedDate.AddDate(0, 0, 1))
+			m.agendaStart = dayStart(m.selectedDate)
+			return cmd, true
+		case key.Matches(msg, prevWeekKey):
+			m.stepAgenda(-1)
+			return nil, true
+		case key.Matches(msg, nextWeekKey):
+			m.stepAgenda(1)
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// selectDate moves the selection to day, fetching the months the current
+// view needs.
+func (m *Model) selectDate(day time.Time) tea.Cmd {
+	m.selectedDate = day
+	m.DatePicker.SetTime(day)
+	m.selected = 0
+	m.filterEventsForSelectedDate()
+	return m.fetchShownMonths()
+}
+
+// shownDays returns the first and the day after the last day shown by the
+// current view.
+func (m *Model) shownDays() (time.Time, time.Time) {
+	switch m.view {
+	case viewWeek:
+		start := weekStart(m.selectedDate)
+		return start, start.AddDate(0, 0, 7)
+	case viewAgenda:
+		return m.agendaStart, m.agendaStart.AddDate(0, 0, agendaDays)
+	}
+	start := dayStart(m.selectedDate)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// fetchShownMonths fetches the months shown by the current view that are
+// neither cached nor already being fetched. The spinner is only shown while
+// the selected day's month is missing.
+func (m *Model) fetchShownMonths() tea.Cmd {
+	start, end := m.shownDays()
+	var cmds []tea.Cmd
+	month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.Local)
+	for ; month.Before(end); month = month.AddDate(0, 1, 0) {
+		monthKey := month.Format("2006-01")
+		if _, ok := m.cachedEvents[monthKey]; ok || m.fetchingMonths[monthKey] {
+			continue
+		}
+		m.fetchingMonths[monthKey] = true
+		cmds = append(cmds, FetchEventsForMonth(m.providers, month))
+	}
+	if len(cmds) > 0 {
+		m.lastFetchTime = time.Now()
+	}
+	if _, ok := m.cachedEvents[m.selectedDate.Format("2006-01")]; !ok {
+		m.loading = true
+	}
+	return tea.Batch(cmds...)
+}
+
+// stepAgenda selects the next (step 1) or previous (step -1) event listed in
+// the agenda, moving to its day.
+func (m *Model) stepAgenda(step int) {
+	type item struct {
+		day   time.Time
+		index int
+	}
+	var items []item
+	for d := 0; d < agendaDays; d++ {
+		day := m.agendaStart.AddDate(0, 0, d)
+		for i := range m.eventsOn(day) {
+			items = append(items, item{day, i})
+		}
+	}
+
+	next := -1
+	for i, it := range items {
+		if sameDay(it.day, m.selectedDate) && it.index == m.selected {
+			next = i + step
+			break
+		}
+	}
+	if next < 0 && len(m.events) == 0 {
+		// Nothing is selected on the selected day: take the nearest event
+		// in the direction of the step.
+		today := dayStart(m.selectedDate)
+		for i, it := range items {
+			if step > 0 && it.day.After(today) {
+				next = i
+				break
+			}
+			if step < 0 && it.day.Before(today) {
+				next = i
+			}
+		}
+	}
+	if next < 0 || next >= len(items) {
+		return
+	}
+	m.selectedDate = items[next].day
+	m.DatePicker.SetTime(items[next].day)
+	m.filterEventsForSelectedDate()
+	m.selected = items[next].index
+}
+
+// eventsOn returns the cached events overlapping day in local time, so
+// events lasting several days or past midnight are listed on each of them.
+func (m *Model) eventsOn(day time.Time) []Event {
+	start := dayStart(day)
+	end := start.AddDate(0, 0, 1)
+	var events []Event
+	for _, event := range m.cachedEvents[start.Format("2006-01")] {
+		if event.overlaps(start, end) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// dayMarks returns a heart for a birthday or anniversary and a dot in the
+// event's color for each of the events on day, three marks at most.
+func (m *Model) dayMarks(day time.Time) string {
+	var marks strings.Builder
+	count := 0
+	if m.hasPersonalDate(day) {
+		marks.WriteString(personalStyle.Render("♥"))
+		count++
+	}
+	for _, e := range m.eventsOn(day) {
+		if count == 3 {
+			break
+		}
+		count++
+		marks.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color(m.eventColor(e))).Render("•"))
+	}
+	return marks.String()
+}
+
+// datepickerView renders the datepicker's month the way its own View does,
+// with the days that have events marked on the padding line below them.
+func (m *Model) datepickerView() string {
+	dp := m.DatePicker
+	styles := dp.Styles
+	month, year := dp.Time.Month(), dp.Time.Year()
+
+	tMonth, tYear := month.String(), strconv.Itoa(year)
+	if dp.Focused == datepicker.FocusHeaderMonth {
+		tMonth = styles.FocusedText.Render(tMonth)
+	} else {
+		tMonth = styles.HeaderText.Render(tMonth)
+	}
+	if dp.Focused == datepicker.FocusHeaderYear {
+		tYear = styles.FocusedText.Render(tYear)
+	} else {
+		tYear = styles.HeaderText.Render(tYear)
+	}
+	title := styles.Header.Render(fmt.Sprintf("%s %s\n", tMonth, tYear))
+
+	weekHeaders := []string{"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"}
+	for i, h := range weekHeaders {
+		weekHeaders[i] = styles.Date.Copy().Inherit(styles.HeaderText).Render(h)
+	}
+	cal := [][]string{weekHeaders}
+
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+	end := weekStart(first.AddDate(0, 1, -1)).AddDate(0, 0, 7)
+	numberStyle := styles.Date.Copy().PaddingBottom(0)
+	for day := weekStart(first); day.Before(end); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Sunday {
+			cal = append(cal, nil)
+		}
+		out, marks := "  ", ""
+		textStyle := styles.Text
+		if day.Month() == month {
+			out = fmt.Sprintf("%02d", day.Day())
+			marks = m.dayMarks(day)
+			if m.isHoliday(day) {
+				textStyle = holidayStyle
+			}
+			if dp.Selected && day.Day() == dp.Time.Day() {
+				if dp.Focused == datepicker.FocusCalendar {
+					textStyle = styles.FocusedText
+				} else {
+					textStyle = styles.SelectedText
+				}
+			}
+		}
+		number := numberStyle.Copy().Inherit(textStyle.Copy()).Render(out)
+		width := lipgloss.Width(number)
+		padding := strings.Repeat(" ", styles.Date.GetPaddingLeft())
+		marks = padding + marks + strings.Repeat(" ", max(0, width-len(padding)-ansi.StringWidth(marks)))
+		cell := number
+		for i := 0; i < styles.Date.GetPaddingBottom(); i++ {
+			if i == 0 {
+				cell += "\n" + marks
+			} else {
+				cell += "\n" + strings.Repeat(" ", width)
+			}
+		}
+		cal[len(cal)-1] = append(cal[len(cal)-1], cell)
+	}
+
+	rows := []string{title}
+	for _, row := range cal {
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Center, row...))
+	}
+	return lipgloss.JoinVertical(lipgloss.Center, rows...)
+}
+
+// weekStart returns the Sunday starting the week of day, matching the
+// datepicker's first column.
+func weekStart(day time.Time) time.Time {
+	start := dayStart(day)
+	return start.AddDate(0, 0, -int(start.Weekday()))
+}
+
+// weekView renders the week of the selected day as seven columns of hour
+// slots, with all-day events in a row under the day names.
+func (m *Model) weekView(width, height int) string {
+	start := weekStart(m.selectedDate)
+	now := time.Now()
+	colWidth := max(2, (width-weekGutterWidth)/7)
+
+	days := make([]time.Time, 7)
+	events := make([][]Event, 7)
+	hasAllDay := false
+	earliest, latest := 8, 0
+	for i := range days {
+		days[i] = start.AddDate(0, 0, i)
+		events[i] = m.eventsOn(days[i])
+		for _, e := range events[i] {
+			if e.AllDay {
+				hasAllDay = true
+				continue
+			}
+			// Parts on other days fill the day from or to midnight.
+			from, to := e.Start.Local(), e.End.Local()
+			if from.Before(days[i]) {
+				earliest = 0
+			} else {
+				earliest = min(earliest, from.Hour())
+			}
+			if to.Before(days[i].AddDate(0, 0, 1)) {
+				latest = max(latest, to.Hour()+min(1, to.Minute()))
+			} else {
+				latest = 24
+			}
+		}
+	}
+
+	lines := []string{viewTitleStyle.Render(ansi.Truncate(dayRange(days[0], days[6]), width, "…"))}
+
+	var header strings.Builder
+	header.WriteString(strings.Repeat(" ", weekGutterWidth))
+	for _, day := range days {
+		label := day.Format("2")
+		if colWidth >= 6 {
+			label = day.Format("Mon")[:2] + " " + label
+		}
+		style := dayHeaderStyle
+		switch {
+		case sameDay(day, m.selectedDate):
+			style = daySelectedStyle.Underline(true)
+		case sameDay(day, now):
+			style = dayTodayStyle
+		}
+		header.WriteString(style.Render(label) + strings.Repeat(" ", max(0, colWidth-len(label))))
+	}
+	lines = append(lines, header.String())
+
+	if hasAllDay {
+		var row strings.Builder
+		row.WriteString(strings.Repeat(" ", weekGutterWidth))
+		for i := range days {
+			var allDay []Event
+			for _, e := range events[i] {
+				if e.AllDay {
+					allDay = append(allDay, e)
+				}
+			}
+			row.WriteString(m.weekCell(allDay, true, colWidth))
+		}
+		lines = append(lines, row.String())
+	}
+
+	rows := height - len(lines)
+	if m.status != "" {
+		rows--
+	}
+	first := min(8, earliest)
+	if latest > first+rows {
+		first = max(0, min(earliest, latest-rows))
+	}
+	first = max(0, min(first, 24-rows))
+	for hour := first; hour < 24 && hour < first+rows; hour++ {
+		var row strings.Builder
+		gutterStyle := weekGridStyle
+		if now.Hour() == hour && !now.Before(days[0]) && now.Before(days[6].AddDate(0, 0, 1)) {
+			gutterStyle = agendaNowStyle
+		}
+		row.WriteString(gutterStyle.Render(fmt.Sprintf("%02d", hour)) + " ")
+		for i, day := range days {
+			slotStart := day.Add(time.Duration(hour) * time.Hour)
+			slotEnd := slotStart.Add(time.Hour)
+			var slot []Event
+			labelled := true
+			for _, e := range events[i] {
+				if e.AllDay {
+					continue
+				}
+				zeroLength := !e.End.After(e.Start) && !e.Start.Before(slotStart) && e.Start.Before(slotEnd)
+				if zeroLength || (e.Start.Before(slotEnd) && e.End.After(slotStart)) {
+					if len(slot) == 0 {
+						// Name an event in the slot it starts in, or at the top.
+						labelled = !e.Start.Before(slotStart) || hour == first
+					}
+					slot = append(slot, e)
+				}
+			}
+			row.WriteString(m.weekCell(slot, labelled, colWidth))
+		}
+		lines = append(lines, row.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// weekCell renders one cell of the week view, colored after its first event
+// and named after it if labelled. A "+" means more events share the cell.
+func (m *Model) weekCell(events []Event, labelled bool, width int) string {
+	if len(events) == 0 {
+		return weekGridStyle.Render("·") + strings.Repeat(" ", width-1)
+	}
+	e := events[0]
+	room := width - 1
+	if len(events) > 1 {
+		room--
+	}
+	label := ""
+	if labelled {
+		label = ansi.Truncate(strings.ReplaceAll(e.Summary, "\n", " "), room, "…")
+	}
+	label += strings.Repeat(" ", max(0, room-ansi.StringWidth(label)))
+	if len(events) > 1 {
+		label += "+"
+	}
+	style := agendaBadgeStyle.Background(lipgloss.Color(m.eventColor(e)))
+	if selected, ok := m.SelectedEvent(); ok && selected.sameAs(e) {
+		style = style.Inherit(weekSelectedStyle)
+	}
+	return style.Render(label) + " "
+}
+
+// agendaView lists the events of the agendaDays days from agendaStart,
+// grouped by day, keeping the selected event in sight.
+func (m *Model) agendaView(width, height int) string {
+	now := time.Now()
+	end := m.agendaStart.AddDate(0, 0, agendaDays-1)
+	lines := []string{viewTitleStyle.Render(ansi.Truncate(dayRange(m.agendaStart, end), width, "…"))}
+	if m.status != "" {
+		height--
+	}
+
+	var body []string
+	selectedLine := 0
+	for d := 0; d < agendaDays; d++ {
+		day := m.agendaStart.AddDate(0, 0, d)
+		events := m.eventsOn(day)
+		holidayLines := m.holidayLines(day, width)
+		isSelected := sameDay(day, m.selectedDate)
+		if len(events) == 0 && len(holidayLines) == 0 && !isSelected {
+			continue
+		}
+
+		label := day.Format("Mon 2 Jan")
+		switch {
+		case sameDay(day, now):
+			label = "Today · " + label
+		case sameDay(day, now.AddDate(0, 0, 1)):
+			label = "Tomorrow · " + label
+		}
+		style := dayHeaderStyle
+		if isSelected {
+			style = daySelectedStyle
+			selectedLine = len(body)
+		}
+		body = append(body, style.Render(ansi.Truncate(label, width, "…")))
+		body = append(body, holidayLines...)
+
+		if len(events) == 0 && len(holidayLines) == 0 {
+			body = append(body, agendaPastStyle.Render("  No events"))
+		}
+		for i, e := range events {
+			selected := isSelected && i == m.selected
+			if selected {
+				selectedLine = len(body)
+			}
+			body = append(body, m.agendaLine(e, day, width, selected, now))
+		}
+	}
+
+	rows := max(0, height-len(lines))
+	first := max(0, min(selectedLine-rows+1, len(body)-rows))
+	body = body[first:min(len(body), first+rows)]
+	return strings.Join(append(lines, body...), "\n")
+}
@@ -0,0 +1,190 @@
+package notes
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"GoDash/internal/config"
+)
+
+// ImageRef is an image referenced by a note, either as ![alt](target) or as
+// an Obsidian style ![[target]] embed.
+type ImageRef struct {
+	Alt    string
+	Target string
+}
+
+var imageRefRegex = regexp.MustCompile(`!\[([^\]]*)\]\(\s*<?([^)>\s]+)>?(?:\s+"[^"]*")?\s*\)|!\[\[([^\]|]+)(?:\|([^\]]*))?\]\]`)
+
+var imageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true,
+	".webp": true, ".svg": true, ".bmp": true, ".avif": true,
+}
+
+func isImageFile(name string) bool {
+	return imageExtensions[strings.ToLower(filepath.Ext(name))]
+}
+
+// AttachmentsDir returns the folder holding the attachments of the note at
+// notePath: "attachments/<note name>" next to the note.
+func AttachmentsDir(notePath string) string {
+	stem := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(notePath), ".enc"), ".md")
+	return filepath.Join(filepath.Dir(notePath), "attachments", stem)
+}
+
+// AttachFile copies the file at src into the attachments folder of the note
+// at notePath and returns a markdown link to the copy, relative to the note.
+// Images are linked with ![...] so they show up in the preview.
+func AttachFile(notePath, src string) (string, error) {
+	src = cleanPastedPath(src)
+	info, err := os.Stat(src)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", src)
+	}
+
+	dir := AttachmentsDir(notePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	ext := filepath.Ext(src)
+	stem := importFilename(strings.TrimSuffix(filepath.Base(src), ext))
+	dst := filepath.Join(dir, stem+ext)
+	for n := 2; ; n++ {
+		if _, err := os.Stat(dst); os.IsNotExist(err) {
+			break
+		}
+		dst = filepath.Join(dir, fmt.Sprintf("%s-%d%s", stem, n, ext))
+	}
+	if err := copyFile(src, dst); err != nil {
+		return "", err
+	}
+
+	link := escapeLinkPath(relLink(filepath.Dir(notePath), dst))
+	if isImageFile(dst) {
+		return fmt.Sprintf("![%s](%s)", stem, link), nil
+	}
+	return fmt.Sprintf("[%s](%s)", filepath.Base(dst), link), nil
+}
+
+// escapeLinkPath percent-encodes each segment of a slash separated path so
+// that it can be used as a markdown link destination, which can't contain
+// spaces.
+func escapeLinkPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// cleanPastedPath undoes the quoting terminals apply to dropped or pasted
+// paths and expands a leading "~/" and file:// URLs.
+func cleanPastedPath(path string) string {
+	path = strings.TrimSpace(path)
+	if len(path) >= 2 && (path[0] == '\'' || path[0] == '"') && path[len(path)-1] == path[0] {
+		path = path[1 : len(path)-1]
+	} else {
+		path = strings.ReplaceAll(path, `\ `, " ")
+	}
+	if u, err := url.Parse(path); err == nil && u.Scheme == "file" {
+		path = u.Path
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// ReplaceImages replaces every image reference outside code blocks with the
+// text returned by repl, which receives the image's index. It returns the new
+// content and the images in order of appearance.
+func ReplaceImages(content string, repl func(i int, img ImageRef) string) (string, []ImageRef) {
+	var images []ImageRef
+	lines := strings.Split(content, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		lines[i] = imageRefRegex.ReplaceAllStringFunc(line, func(ref string) string {
+			match := imageRefRegex.FindStringSubmatch(ref)
+			img := ImageRef{Alt: match[1], Target: match[2]}
+			if match[3] != "" {
+				// ![[embeds]] may also embed notes, which are left alone.
+				if !isImageFile(match[3]) {
+					return ref
+				}
+				img = ImageRef{Alt: match[4], Target: strings.TrimSpace(match[3])}
+			}
+			if img.Alt == "" {
+				img.Alt = filepath.Base(img.Target)
+			}
+			images = append(images, img)
+			return repl(len(images)-1, img)
+		})
+	}
+	return strings.Join(lines, "\n"), images
+}
+
+// ResolveAttachment turns the target of a link in the note at notePath into a
+// URL that can be handed to the system viewer. Relative paths are resolved
+// against the note's folder; bare names of Obsidian embeds are looked up
+// anywhere in the notes directory.
+func ResolveAttachment(notePath, target string) (string, error) {
+	if u, err := url.Parse(target); err == nil && u.Scheme != "" && len(u.Scheme) > 1 {
+		return target, nil
+	}
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+
+	path := filepath.FromSlash(target)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(notePath), path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		found, findErr := findInNotesDir(filepath.Base(target))
+		if findErr != nil {
+			return "", fmt.Errorf("attachment %s not found", target)
+		}
+		path = found
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String(), nil
+}
+
+// findInNotesDir returns the first file named name inside the notes directory.
+func findInNotesDir(name string) (string, error) {
+	notesDir, err := config.GetNotesDir()
+	if err != nil {
+		return "", err
+	}
+	var found string
+	err = filepath.WalkDir(notesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && d.Name() == name {
+			found = path
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if err == nil && found == "" {
+		err = os.ErrNotExist
+	}
+	return found, err
+}
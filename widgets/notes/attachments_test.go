@@ -0,0 +1,41 @@
+package notes
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAttachFileNoteWithSpaces(t *testing.T) {
+	dir := t.TempDir()
+	notePath := filepath.Join(dir, "My Note.md")
+	src := filepath.Join(t.TempDir(), "chart.png")
+	if err := os.WriteFile(src, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := AttachFile(notePath, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "![chart](attachments/My%20Note/chart.png)"; link != want {
+		t.Errorf("AttachFile() = %q, want %q", link, want)
+	}
+
+	_, images := ReplaceImages(link, func(i int, img ImageRef) string { return "" })
+	if len(images) != 1 {
+		t.Fatalf("ReplaceImages() found %d images in %q, want 1", len(images), link)
+	}
+	got, err := ResolveAttachment(notePath, images[0].Target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "attachments", "My Note", "chart.png"); filepath.FromSlash(u.Path) != want {
+		t.Errorf("ResolveAttachment() = %q, want a URL for %s", got, want)
+	}
+}
@@ -0,0 +1,190 @@
+package notes
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"GoDash/internal/config"
+)
+
+// ChecklistItem is a markdown task list entry ("- [ ] item") found in a note.
+type ChecklistItem struct {
+	Path      string // note file the item belongs to
+	NoteTitle string
+	Line      int // zero-based line number in the note
+	Text      string
+	Done      bool
+}
+
+// These methods implement the list.Item interface.
+func (c ChecklistItem) FilterValue() string { return c.Text }
+
+// PromoteToTodoMsg is sent when a checklist item should become a todo task.
+type PromoteToTodoMsg struct {
+	Title string
+}
+
+var checklistRegex = regexp.MustCompile(`^(\s*[-*+]\s+\[)([ xX])(\]\s+)(.*)$`)
+
+// ParseChecklist returns every task list item in content, skipping fenced
+// code blocks.
+func ParseChecklist(content string) []ChecklistItem {
+	var items []ChecklistItem
+	inFence := false
+	for i, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if match := checklistRegex.FindStringSubmatch(line); match != nil {
+			items = append(items, ChecklistItem{
+				Line: i,
+				Text: strings.TrimSpace(match[4]),
+				Done: match[2] != " ",
+			})
+		}
+	}
+	return items
+}
+
+// ToggleChecklistLine flips the checkbox on the given line of content. It
+// reports false if that line is not a task list item.
+func ToggleChecklistLine(content string, line int) (string, bool) {
+	lines := strings.Split(content, "\n")
+	if line < 0 || line >= len(lines) {
+		return content, false
+	}
+	match := checklistRegex.FindStringSubmatch(lines[line])
+	if match == nil {
+		return content, false
+	}
+	mark := "x"
+	if match[2] != " " {
+		mark = " "
+	}
+	lines[line] = match[1] + mark + match[3] + match[4]
+	return strings.Join(lines, "\n"), true
+}
+
+// ToggleChecklistItem flips the checkbox of item in its note file on disk.
+func ToggleChecklistItem(item ChecklistItem) error {
+	content, err := os.ReadFile(item.Path)
+	if err != nil {
+		return err
+	}
+	updated, ok := ToggleChecklistLine(string(content), item.Line)
+	if !ok {
+		return fmt.Errorf("line %d of %s is no longer a checklist item", item.Line+1, filepath.Base(item.Path))
+	}
+	return os.WriteFile(item.Path, []byte(updated), 0644)
+}
+
+// OpenChecklistItems collects the unchecked task list items of every note.
+func OpenChecklistItems() ([]ChecklistItem, error) {
+	notesDir, err := config.GetNotesDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []ChecklistItem
+	err = filepath.WalkDir(notesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != notesDir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "attachments") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".md") {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, item := range ParseChecklist(string(content)) {
+			if item.Done {
+				continue
+			}
+			item.Path = path
+			item.NoteTitle = titleFromFilename(d.Name())
+			items = append(items, item)
+		}
+		return nil
+	})
+	return items, err
+}
+
+type checklistDelegate struct{}
+
+func (d checklistDelegate) Height() int                               { return 1 }
+func (d checklistDelegate) Spacing() int                              { return 0 }
+func (d checklistDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd { return nil }
+func (d checklistDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	item, ok := listItem.(ChecklistItem)
+	if !ok {
+		return
+	}
+
+	source := lipgloss.NewStyle().Foreground(lipgloss.Color("#5c6370")).Render(" — " + item.NoteTitle)
+	str := "[ ] " + item.Text
+	if index == m.Index() {
+		fmt.Fprint(w, lipgloss.NewStyle().Foreground(lipgloss.Color("#56b6c2")).Render("> "+str)+source)
+	} else {
+		fmt.Fprint(w, lipgloss.NewStyle().PaddingLeft(2).Render(str)+source)
+	}
+}
+
+func newChecklistList() list.Model {
+	l := list.New(nil, checklistDelegate{}, 0, 0)
+	l.SetShowHelp(false)
+	l.SetShowStatusBar(false)
+	l.SetShowTitle(false)
+	return l
+}
+
+// loadChecklist refreshes the aggregated checklist view.
+func (m *Model) loadChecklist() {
+	items, err := OpenChecklistItems()
+	if err != nil {
+		m.setChecklistStatus("Error loading checklist items: " + err.Error())
+	}
+	listItems := make([]list.Item, len(items))
+	for i, item := range items {
+		listItems[i] = item
+	}
+	m.Checklist.SetItems(listItems)
+}
+
+// setChecklistStatus shows status below the checklist, or hides the status
+// line if status is empty.
+func (m *Model) setChecklistStatus(status string) {
+	m.checklistStatus = status
+	m.resizeChecklist()
+}
+
+func (m *Model) resizeChecklist() {
+	height := m.height
+	if m.checklistStatus != "" {
+		height -= lipgloss.Height(m.checklistStatusView())
+	}
+	m.Checklist.SetSize(m.width, height)
+}
+
+func (m *Model) checklistStatusView() string {
+	return checklistStatusStyle.Width(m.width).Render(m.checklistStatus)
+}
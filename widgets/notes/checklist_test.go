@@ -0,0 +1,73 @@
+package notes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseChecklist(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []ChecklistItem
+	}{
+		{
+			name:    "open and done",
+			content: "# List\n- [ ] milk\n* [x] bread\n+ [X]  eggs \n",
+			want: []ChecklistItem{
+				{Line: 1, Text: "milk"},
+				{Line: 2, Text: "bread", Done: true},
+				{Line: 3, Text: "eggs", Done: true},
+			},
+		},
+		{
+			name:    "nested",
+			content: "- [ ] trip\n    - [x] tickets",
+			want: []ChecklistItem{
+				{Line: 0, Text: "trip"},
+				{Line: 1, Text: "tickets", Done: true},
+			},
+		},
+		{
+			name:    "code blocks are skipped",
+			content: "```\n- [ ] not a task\n```\n- [ ] task",
+			want:    []ChecklistItem{{Line: 3, Text: "task"}},
+		},
+		{
+			name:    "plain list items",
+			content: "- milk\n- [] bread\n-[ ] eggs",
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseChecklist(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseChecklist() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToggleChecklistLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		want    string
+		ok      bool
+	}{
+		{"check", "a\n- [ ] milk\nb", 1, "a\n- [x] milk\nb", true},
+		{"uncheck", "  * [X] milk", 0, "  * [ ] milk", true},
+		{"not a task", "- milk", 0, "- milk", false},
+		{"out of range", "- [ ] milk", 1, "- [ ] milk", false},
+		{"negative line", "- [ ] milk", -1, "- [ ] milk", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ToggleChecklistLine(tt.content, tt.line)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("ToggleChecklistLine() = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
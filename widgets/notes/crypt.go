@@ -0,0 +1,155 @@
+package notes
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// EncryptedSuffix is the file extension of encrypted notes.
+const EncryptedSuffix = ".md.enc"
+
+// encryptedHeader starts every encrypted note file so it can be recognised
+// even if it was renamed.
+const encryptedHeader = "GODASH-ENCRYPTED-NOTE v1\n"
+
+const (
+	saltSize = 16
+	keySize  = 32
+	// scrypt parameters recommended for interactive logins.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var (
+	// ErrWrongPassphrase is returned when an encrypted note cannot be
+	// decrypted, which almost always means the passphrase was mistyped.
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted note")
+	errNotEncrypted    = errors.New("note is not encrypted")
+)
+
+// IsEncryptedPath reports whether path names an encrypted note.
+func IsEncryptedPath(path string) bool {
+	return strings.HasSuffix(path, EncryptedSuffix)
+}
+
+// EncryptNote seals plaintext with AES-256-GCM using a key derived from
+// passphrase with scrypt. The result is ASCII armored so it stays diffable.
+func EncryptNote(plaintext []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := newNoteCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := append(append(salt, nonce...), gcm.Seal(nil, nonce, plaintext, []byte(encryptedHeader))...)
+
+	var out bytes.Buffer
+	out.WriteString(encryptedHeader)
+	encoded := base64.StdEncoding.EncodeToString(sealed)
+	for len(encoded) > 76 {
+		out.WriteString(encoded[:76] + "\n")
+		encoded = encoded[76:]
+	}
+	out.WriteString(encoded + "\n")
+	return out.Bytes(), nil
+}
+
+// DecryptNote reverses EncryptNote. It returns ErrWrongPassphrase if the
+// passphrase does not match.
+func DecryptNote(data []byte, passphrase string) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(encryptedHeader)) {
+		return nil, errNotEncrypted
+	}
+	encoded := strings.Join(strings.Fields(string(data[len(encryptedHeader):])), "")
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode encrypted note: %w", err)
+	}
+	if len(sealed) < saltSize {
+		return nil, ErrWrongPassphrase
+	}
+
+	salt := sealed[:saltSize]
+	gcm, err := newNoteCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < saltSize+gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	nonce := sealed[saltSize : saltSize+gcm.NonceSize()]
+	plaintext, err := gcm.Open(nil, nonce, sealed[saltSize+gcm.NonceSize():], []byte(encryptedHeader))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+func newNoteCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptNoteFile encrypts the plaintext note at path, writes it next to the
+// original with EncryptedSuffix and removes the plaintext file along with its
+// autosaved draft. It returns the path of the encrypted note.
+func EncryptNoteFile(path, passphrase string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := EncryptNote(content, passphrase)
+	if err != nil {
+		return "", err
+	}
+	encryptedPath := strings.TrimSuffix(path, ".md") + EncryptedSuffix
+	if err := os.WriteFile(encryptedPath, sealed, 0600); err != nil {
+		return "", err
+	}
+	if err := os.Remove(path); err != nil {
+		return "", err
+	}
+	return encryptedPath, DeleteDraft(path)
+}
+
+// DecryptNoteFile permanently removes the encryption from the note at path,
+// returning the path of the plaintext note.
+func DecryptNoteFile(path, passphrase string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := DecryptNote(content, passphrase)
+	if err != nil {
+		return "", err
+	}
+	plainPath := strings.TrimSuffix(path, EncryptedSuffix) + ".md"
+	if err := os.WriteFile(plainPath, plaintext, 0644); err != nil {
+		return "", err
+	}
+	return plainPath, os.Remove(path)
+}
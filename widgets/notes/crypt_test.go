@@ -0,0 +1,75 @@
+package notes
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncryptDecryptNote(t *testing.T) {
+	tests := []struct {
+		name      string
+		plaintext string
+		decryptAs string
+		wantErr   error
+	}{
+		{"round trip", "# Secret\n\nThe code is 1234.\n", "hunter2", nil},
+		{"empty note", "", "hunter2", nil},
+		{"long note", string(bytes.Repeat([]byte("line of text\n"), 500)), "hunter2", nil},
+		{"wrong passphrase", "# Secret", "hunter3", ErrWrongPassphrase},
+		{"empty passphrase", "# Secret", "", ErrWrongPassphrase},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := EncryptNote([]byte(tt.plaintext), "hunter2")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.plaintext != "" && bytes.Contains(sealed, []byte(tt.plaintext)) {
+				t.Fatal("encrypted note contains the plaintext")
+			}
+			got, err := DecryptNote(sealed, tt.decryptAs)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DecryptNote() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && string(got) != tt.plaintext {
+				t.Errorf("DecryptNote() = %q, want %q", got, tt.plaintext)
+			}
+		})
+	}
+}
+
+func TestDecryptNoteNotEncrypted(t *testing.T) {
+	if _, err := DecryptNote([]byte("# Plain note"), "hunter2"); !errors.Is(err, errNotEncrypted) {
+		t.Errorf("DecryptNote() error = %v, want %v", err, errNotEncrypted)
+	}
+}
+
+func TestEncryptNoteFileDeletesDraft(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "Secret.md")
+	writeTestFile(t, path, "# Secret")
+	if err := SaveDraft(path, []byte("# Secret draft")); err != nil {
+		t.Fatal(err)
+	}
+
+	encryptedPath, err := EncryptNoteFile(path, "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("plaintext note still exists: %v", err)
+	}
+	if _, _, err := LoadDraft(path); !os.IsNotExist(err) {
+		t.Errorf("plaintext draft still exists: %v", err)
+	}
+	plainPath, err := DecryptNoteFile(encryptedPath, "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, err := os.ReadFile(plainPath); err != nil || string(content) != "# Secret" {
+		t.Errorf("decrypted note = %q, %v", content, err)
+	}
+}
@@ -0,0 +1,144 @@
+package notes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"GoDash/internal/config"
+)
+
+// draftPath returns the autosave location for the note at notePath. The name
+// is derived from a hash of the path so notes with equal names in different
+// folders don't collide.
+func draftPath(notePath string) (string, error) {
+	draftsDir, err := config.GetDraftsDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(notePath))
+	base := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(notePath), ".enc"), ".md")
+	return filepath.Join(draftsDir, base+"-"+hex.EncodeToString(sum[:8])+".draft"), nil
+}
+
+// SaveDraft writes an autosaved copy of unsaved edits to the note at notePath.
+func SaveDraft(notePath string, content []byte) error {
+	path, err := draftPath(notePath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0600)
+}
+
+// LoadDraft returns the autosaved draft of the note at notePath and when it
+// was written. os.ErrNotExist is returned when there is no draft.
+func LoadDraft(notePath string) ([]byte, time.Time, error) {
+	path, err := draftPath(notePath)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return content, info.ModTime(), nil
+}
+
+// DeleteDraft removes the autosaved draft of the note at notePath, if any.
+func DeleteDraft(notePath string) error {
+	path, err := draftPath(notePath)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// DiffOp is the kind of change a DiffLine represents.
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffDelete
+	DiffInsert
+)
+
+// DiffLine is a single line of a line-based diff.
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// maxDiffCells bounds the LCS table; larger inputs fall back to a plain
+// delete-then-insert diff.
+const maxDiffCells = 4_000_000
+
+// LineDiff returns the line-based difference turning a into b, computed from
+// their longest common subsequence.
+func LineDiff(a, b string) []DiffLine {
+	aLines := strings.Split(a, "\n")
+	bLines := strings.Split(b, "\n")
+	n, m := len(aLines), len(bLines)
+
+	if n*m > maxDiffCells {
+		diff := make([]DiffLine, 0, n+m)
+		for _, line := range aLines {
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: line})
+		}
+		for _, line := range bLines {
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: line})
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the LCS length of aLines[i:] and bLines[j:].
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if aLines[i] == bLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff []DiffLine
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case aLines[i] == bLines[j]:
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: aLines[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: aLines[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: bLines[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		diff = append(diff, DiffLine{Op: DiffDelete, Text: aLines[i]})
+	}
+	for ; j < m; j++ {
+		diff = append(diff, DiffLine{Op: DiffInsert, Text: bLines[j]})
+	}
+	return diff
+}
@@ -0,0 +1,408 @@
+package notes
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+
+	"GoDash/internal/config"
+)
+
+// ExportOptions controls how the notes directory is exported to HTML.
+type ExportOptions struct {
+	// Site additionally writes an index page and one page per tag, turning
+	// the output directory into a small browsable static site.
+	Site bool
+}
+
+// exportedNote is a note prepared for HTML export.
+type exportedNote struct {
+	Title   string
+	Tags    []string
+	relPath string // path of the source file relative to the notes dir
+	body    string
+}
+
+// htmlPath returns the output path of the note relative to the export root.
+func (n exportedNote) htmlPath() string {
+	return strings.TrimSuffix(n.relPath, ".md") + ".html"
+}
+
+var wikiLinkRegex = regexp.MustCompile(`\[\[([^\]|#]*)(?:#([^\]|]+))?(?:\|([^\]]+))?\]\]`)
+
+// ExportHTML converts every note in the notes directory into a standalone HTML
+// file inside outDir, keeping the folder structure of the notes directory.
+// [[wiki links]] between notes are rewritten to relative links. It returns
+// the number of notes exported.
+func ExportHTML(outDir string, opts ExportOptions) (int, error) {
+	notesDir, err := config.GetNotesDir()
+	if err != nil {
+		return 0, err
+	}
+	return exportDir(notesDir, outDir, opts)
+}
+
+func exportDir(notesDir, outDir string, opts ExportOptions) (int, error) {
+	collected, err := collectNotesForExport(notesDir)
+	if err != nil {
+		return 0, err
+	}
+
+	// Index notes by every name a wiki link may use to refer to them.
+	byName := make(map[string]exportedNote)
+	for _, n := range collected {
+		stem := strings.TrimSuffix(filepath.Base(n.relPath), ".md")
+		for _, name := range []string{n.Title, stem, titleFromFilename(stem), strings.TrimSuffix(n.relPath, ".md")} {
+			key := strings.ToLower(strings.TrimSpace(name))
+			if _, exists := byName[key]; !exists {
+				byName[key] = n
+			}
+		}
+	}
+
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM, extension.Footnote),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	)
+
+	for _, n := range collected {
+		pageDir := filepath.Dir(n.htmlPath())
+		source := resolveWikiLinks(n.body, func(target string) (string, bool) {
+			linked, ok := byName[strings.ToLower(strings.TrimSpace(target))]
+			if !ok {
+				return "", false
+			}
+			return relLink(pageDir, linked.htmlPath()), true
+		})
+
+		var buf bytes.Buffer
+		if err := md.Convert([]byte(source), &buf); err != nil {
+			return 0, fmt.Errorf("could not convert %s: %w", n.relPath, err)
+		}
+
+		page := exportPage{
+			Title: n.Title,
+			Body:  template.HTML(buf.String()),
+			Site:  opts.Site,
+			Index: relLink(pageDir, "index.html"),
+		}
+		for _, tag := range n.Tags {
+			// Tag pages only exist in sites; otherwise tags are plain text.
+			link := exportLink{Title: "#" + tag}
+			if opts.Site {
+				link.Href = relLink(pageDir, tagPagePath(tag))
+			}
+			page.Tags = append(page.Tags, link)
+		}
+		if err := writeExportPage(filepath.Join(outDir, n.htmlPath()), page); err != nil {
+			return 0, err
+		}
+		if err := copyAttachments(notesDir, outDir, n); err != nil {
+			return 0, fmt.Errorf("could not copy the attachments of %s: %w", n.relPath, err)
+		}
+	}
+
+	if opts.Site {
+		if err := writeSitePages(outDir, collected); err != nil {
+			return 0, err
+		}
+	}
+	return len(collected), nil
+}
+
+// collectNotesForExport walks the notes directory and reads every markdown
+// file, skipping attachment folders and hidden folders such as draft stores.
+func collectNotesForExport(notesDir string) ([]exportedNote, error) {
+	var collected []exportedNote
+	err := filepath.WalkDir(notesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != notesDir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "attachments") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".md") {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(notesDir, path)
+		if err != nil {
+			return err
+		}
+
+		fm, body := splitFrontMatter(string(content))
+		title := fm.Title
+		if title == "" {
+			for _, line := range strings.Split(body, "\n") {
+				if match := headingRegex.FindStringSubmatch(line); match != nil {
+					title = strings.TrimSpace(match[1])
+					break
+				}
+			}
+		}
+		if title == "" {
+			title = titleFromFilename(d.Name())
+		}
+
+		collected = append(collected, exportedNote{
+			Title:   title,
+			Tags:    noteTags(fm, body),
+			relPath: filepath.ToSlash(rel),
+			body:    body,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(collected, func(i, j int) bool { return collected[i].relPath < collected[j].relPath })
+	return collected, nil
+}
+
+// copyAttachments copies the attachments folder of the note and the local
+// files its images point to into outDir, at the same paths relative to the
+// notes directory, so the links of the exported page keep working.
+func copyAttachments(notesDir, outDir string, n exportedNote) error {
+	notePath := filepath.Join(notesDir, filepath.FromSlash(n.relPath))
+	var files []string
+	err := filepath.WalkDir(AttachmentsDir(notePath), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return filepath.SkipAll
+			}
+			return err
+		}
+		if d.Type().IsRegular() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, images := ReplaceImages(n.body, func(i int, img ImageRef) string { return "" })
+	for _, img := range images {
+		target, err := url.PathUnescape(img.Target)
+		if err != nil || filepath.IsAbs(target) || strings.Contains(target, "://") {
+			continue
+		}
+		path := filepath.Join(filepath.Dir(notePath), filepath.FromSlash(target))
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			files = append(files, path)
+		}
+	}
+
+	for _, path := range files {
+		rel, err := filepath.Rel(notesDir, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue // outside the notes directory
+		}
+		dst := filepath.Join(outDir, rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := copyFile(path, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveWikiLinks rewrites [[Target]], [[Target|Label]] and [[Target#Heading]]
+// into regular markdown links. Unresolvable links are left as their label.
+// Fenced code blocks are never touched.
+func resolveWikiLinks(body string, resolve func(target string) (string, bool)) string {
+	lines := strings.Split(body, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		lines[i] = wikiLinkRegex.ReplaceAllStringFunc(line, func(match string) string {
+			parts := wikiLinkRegex.FindStringSubmatch(match)
+			target, heading, label := parts[1], parts[2], parts[3]
+			if label == "" {
+				label = target
+				if label == "" {
+					label = heading
+				}
+			}
+
+			href := ""
+			if target != "" {
+				resolved, ok := resolve(target)
+				if !ok {
+					return label
+				}
+				href = resolved
+			}
+			if heading != "" {
+				href += "#" + headingAnchor(heading)
+			}
+			return fmt.Sprintf("[%s](%s)", label, href)
+		})
+	}
+	return strings.Join(lines, "\n")
+}
+
+// headingAnchor mirrors the ids generated by goldmark's automatic heading IDs.
+func headingAnchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(heading)) {
+		switch {
+		case r == ' ' || r == '-':
+			b.WriteRune('-')
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r > 127:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func tagPagePath(tag string) string {
+	return "tags/" + sanitizeFilename(strings.ReplaceAll(strings.ToLower(tag), "/", "-")) + ".html"
+}
+
+// relLink returns the slash separated link from pages in dir to target, both
+// relative to the export root.
+func relLink(dir, target string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(dir), filepath.FromSlash(target))
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
+
+// writeSitePages writes the index page and one page per tag.
+func writeSitePages(outDir string, collected []exportedNote) error {
+	index := exportPage{Title: "Notes", Site: true, Index: "index.html"}
+	tagged := make(map[string][]exportLink)
+	tagNames := make(map[string]string)
+	for _, n := range collected {
+		index.Links = append(index.Links, exportLink{Title: n.Title, Href: n.htmlPath()})
+		for _, tag := range n.Tags {
+			key := strings.ToLower(tag)
+			tagNames[key] = tag
+			tagged[key] = append(tagged[key], exportLink{Title: n.Title, Href: relLink("tags", n.htmlPath())})
+		}
+	}
+
+	keys := make([]string, 0, len(tagged))
+	for key := range tagged {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		tag := tagNames[key]
+		index.Tags = append(index.Tags, exportLink{Title: "#" + tag, Href: tagPagePath(tag)})
+		page := exportPage{
+			Title: "#" + tag,
+			Site:  true,
+			Index: "../index.html",
+			Links: tagged[key],
+		}
+		if err := writeExportPage(filepath.Join(outDir, filepath.FromSlash(tagPagePath(tag))), page); err != nil {
+			return err
+		}
+	}
+
+	return writeExportPage(filepath.Join(outDir, "index.html"), index)
+}
+
+type exportLink struct {
+	Title string
+	Href  string
+}
+
+type exportPage struct {
+	Title string
+	Body  template.HTML
+	Site  bool
+	Index string
+	Tags  []exportLink
+	Links []exportLink
+}
+
+func writeExportPage(path string, page exportPage) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := exportTemplate.Execute(&buf, page); err != nil {
+		return fmt.Errorf("could not render %s: %w", path, err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// exportTemplate renders every exported page using the One Dark palette.
+var exportTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{.Title}}</title>
+    <style>
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
+            background: #282c34;
+            color: #abb2bf;
+            line-height: 1.6;
+            margin: 0;
+        }
+        main { max-width: 800px; margin: 0 auto; padding: 30px 20px; }
+        nav { border-bottom: 1px solid #3e4451; padding-bottom: 10px; margin-bottom: 20px; }
+        h1, h2, h3, h4, h5, h6 { color: #e06c75; }
+        a { color: #61afef; }
+        code, pre { font-family: 'JetBrains Mono', monospace; background: #21252b; }
+        pre { padding: 12px; border-radius: 6px; overflow-x: auto; }
+        blockquote { border-left: 3px solid #3e4451; margin-left: 0; padding-left: 15px; color: #7f848e; }
+        table { border-collapse: collapse; }
+        th, td { border: 1px solid #3e4451; padding: 4px 10px; }
+        .tags a, .tags span { color: #98c379; margin-right: 8px; }
+    </style>
+</head>
+<body>
+<main>
+{{- if .Site}}
+    <nav><a href="{{.Index}}">Notes</a></nav>
+{{- end}}
+{{- if .Tags}}
+    <p class="tags">{{range .Tags}}{{if .Href}}<a href="{{.Href}}">{{.Title}}</a>{{else}}<span>{{.Title}}</span>{{end}}{{end}}</p>
+{{- end}}
+{{- if .Links}}
+    <h1>{{.Title}}</h1>
+    <ul>
+    {{- range .Links}}
+        <li><a href="{{.Href}}">{{.Title}}</a></li>
+    {{- end}}
+    </ul>
+{{- end}}
+{{.Body}}
+</main>
+</body>
+</html>
+`))
@@ -0,0 +1,81 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExportDir(t *testing.T) {
+	notesDir, outDir := t.TempDir(), t.TempDir()
+	writeTestFile(t, filepath.Join(notesDir, "Trip.md"), "---\ntags: [travel]\n---\n# Trip\n\n![map](attachments/Trip/map.png)\n")
+	writeTestFile(t, filepath.Join(notesDir, "attachments", "Trip", "map.png"), "png")
+	writeTestFile(t, filepath.Join(notesDir, "attachments", "Trip", "notes.md"), "# Not a note")
+	writeTestFile(t, filepath.Join(notesDir, "images", "logo.png"), "png")
+	writeTestFile(t, filepath.Join(notesDir, "Work", "Plan.md"), "# Plan\n\n![logo](../images/logo.png) See [[Trip]].\n")
+
+	tests := []struct {
+		name     string
+		site     bool
+		tagLink  bool
+		tagPages bool
+	}{
+		{name: "plain", site: false},
+		{name: "site", site: true, tagLink: true, tagPages: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(outDir, tt.name)
+			count, err := exportDir(notesDir, out, ExportOptions{Site: tt.site})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != 2 {
+				t.Errorf("exported %d notes, want 2", count)
+			}
+
+			trip, err := os.ReadFile(filepath.Join(out, "Trip.html"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(string(trip), `href="tags/travel.html"`); got != tt.tagLink {
+				t.Errorf("tag linked = %v, want %v", got, tt.tagLink)
+			}
+			if !strings.Contains(string(trip), "#travel") {
+				t.Error("tag missing from the page")
+			}
+			_, err = os.Stat(filepath.Join(out, "tags", "travel.html"))
+			if got := err == nil; got != tt.tagPages {
+				t.Errorf("tag page written = %v, want %v", got, tt.tagPages)
+			}
+
+			for _, path := range []string{"attachments/Trip/map.png", "attachments/Trip/notes.md", "images/logo.png"} {
+				if _, err := os.Stat(filepath.Join(out, filepath.FromSlash(path))); err != nil {
+					t.Errorf("%s not copied: %v", path, err)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(out, "attachments", "Trip", "notes.html")); err == nil {
+				t.Error("an attachment was exported as a note")
+			}
+
+			plan, err := os.ReadFile(filepath.Join(out, "Work", "Plan.html"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(plan), `href="../Trip.html"`) {
+				t.Error("wiki link not resolved")
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package notes
+
+import (
+	"regexp"
+	"strings"
+)
+
+// frontMatter holds the few YAML front matter fields GoDash understands.
+type frontMatter struct {
+	Title string
+	Tags  []string
+}
+
+var (
+	inlineTagRegex = regexp.MustCompile(`(?:^|\s)#([A-Za-z][\w/-]*)`)
+	headingRegex   = regexp.MustCompile(`^#\s+(.+)$`)
+	numPrefixRegex = regexp.MustCompile(`^\d+\s`)
+)
+
+// splitFrontMatter separates a leading "---" delimited front matter block from
+// the note body. Only simple "key: value" pairs and tag lists are parsed.
+func splitFrontMatter(content string) (frontMatter, string) {
+	var fm frontMatter
+
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, "---\n") {
+		return fm, content
+	}
+	end := strings.Index(normalized[4:], "\n---")
+	if end < 0 {
+		return fm, content
+	}
+	block := normalized[4 : 4+end]
+	body := strings.TrimPrefix(normalized[4+end+4:], "\n")
+
+	var currentKey string
+	for _, line := range strings.Split(block, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		// Continuation of a YAML list, e.g. "tags:\n  - work"
+		if strings.HasPrefix(trimmed, "- ") {
+			if currentKey == "tags" {
+				fm.Tags = append(fm.Tags, cleanTag(strings.TrimPrefix(trimmed, "- ")))
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		currentKey = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		switch currentKey {
+		case "title":
+			fm.Title = strings.Trim(value, `"'`)
+		case "tags":
+			value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+			for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+				if tag = cleanTag(tag); tag != "" {
+					fm.Tags = append(fm.Tags, tag)
+				}
+			}
+		}
+	}
+	return fm, body
+}
+
+func cleanTag(tag string) string {
+	return strings.TrimPrefix(strings.Trim(strings.TrimSpace(tag), `"'`), "#")
+}
+
+// noteTags returns the front matter tags followed by any inline #tags found
+// outside of code blocks, without duplicates.
+func noteTags(fm frontMatter, body string) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	add := func(tag string) {
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		tags = append(tags, tag)
+	}
+
+	for _, tag := range fm.Tags {
+		add(tag)
+	}
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || headingRegex.MatchString(line) {
+			continue
+		}
+		for _, match := range inlineTagRegex.FindAllStringSubmatch(line, -1) {
+			add(match[1])
+		}
+	}
+	return tags
+}
+
+// titleFromFilename turns a note filename like "01-Welcome-to-GoDash.md" into
+// the display title "Welcome to GoDash".
+func titleFromFilename(name string) string {
+	title := strings.TrimSuffix(strings.TrimSuffix(name, ".enc"), ".md")
+	title = strings.ReplaceAll(title, "-", " ") // Replace hyphens with spaces for display
+	return numPrefixRegex.ReplaceAllString(title, "")
+}
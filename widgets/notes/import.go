@@ -0,0 +1,540 @@
+package notes
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"GoDash/internal/config"
+)
+
+// ImportFormat names the kind of source an import reads from.
+type ImportFormat string
+
+const (
+	ImportAuto     ImportFormat = "auto"
+	ImportMarkdown ImportFormat = "markdown"
+	ImportObsidian ImportFormat = "obsidian"
+	ImportJoplin   ImportFormat = "joplin"
+)
+
+// ImportOptions controls how notes are imported into the notes directory.
+type ImportOptions struct {
+	Format ImportFormat
+	// Into is a folder inside the notes directory to import into.
+	Into string
+	// DryRun only reports what would be created or skipped.
+	DryRun bool
+	// Overwrite replaces notes that already exist instead of skipping them.
+	Overwrite bool
+}
+
+// ImportEntry describes what happens to a single file of the import source.
+type ImportEntry struct {
+	Source     string // path relative to the import source
+	Target     string // path relative to the notes directory
+	Attachment bool
+	Skipped    bool
+	Reason     string // why the entry was skipped
+}
+
+// ImportReport lists the outcome of an import, or of a dry run.
+type ImportReport struct {
+	Format  ImportFormat
+	Entries []ImportEntry
+}
+
+// Counts returns the number of imported notes and attachments, and the number
+// of skipped entries.
+func (r ImportReport) Counts() (notes, attachments, skipped int) {
+	for _, e := range r.Entries {
+		switch {
+		case e.Skipped:
+			skipped++
+		case e.Attachment:
+			attachments++
+		default:
+			notes++
+		}
+	}
+	return notes, attachments, skipped
+}
+
+// importItem is a planned write. Either content is set, or the file at
+// srcPath is copied.
+type importItem struct {
+	entry   ImportEntry
+	content []byte
+	srcPath string
+}
+
+// importPlan collects the items of an import and keeps targets unique.
+type importPlan struct {
+	destDir   string
+	overwrite bool
+	items     []importItem
+	targets   map[string]bool
+}
+
+// ImportNotes imports an Obsidian vault, a Joplin export or a folder of
+// markdown files at src into the notes directory.
+func ImportNotes(src string, opts ImportOptions) (ImportReport, error) {
+	notesDir, err := config.GetNotesDir()
+	if err != nil {
+		return ImportReport{}, err
+	}
+	if opts.Into != "" && !filepath.IsLocal(opts.Into) {
+		return ImportReport{}, fmt.Errorf("import folder %q must be inside the notes directory", opts.Into)
+	}
+	return importInto(src, notesDir, opts)
+}
+
+func importInto(src, notesDir string, opts ImportOptions) (ImportReport, error) {
+	format := opts.Format
+	if format == "" || format == ImportAuto {
+		detected, err := DetectImportFormat(src)
+		if err != nil {
+			return ImportReport{}, err
+		}
+		format = detected
+	}
+
+	// A JEX file is a tar archive of a Joplin RAW export.
+	if strings.EqualFold(filepath.Ext(src), ".jex") {
+		tmpDir, err := os.MkdirTemp("", "godash-jex-")
+		if err != nil {
+			return ImportReport{}, err
+		}
+		defer os.RemoveAll(tmpDir)
+		if err := extractTar(src, tmpDir); err != nil {
+			return ImportReport{}, fmt.Errorf("could not read %s: %w", src, err)
+		}
+		src = tmpDir
+	}
+
+	plan := &importPlan{
+		destDir:   filepath.Join(notesDir, opts.Into),
+		overwrite: opts.Overwrite,
+		targets:   make(map[string]bool),
+	}
+	var err error
+	switch format {
+	case ImportJoplin:
+		err = planJoplinImport(src, plan)
+	case ImportObsidian, ImportMarkdown:
+		err = planMarkdownImport(src, format, plan)
+	default:
+		err = fmt.Errorf("unknown import format %q", format)
+	}
+	if err != nil {
+		return ImportReport{}, err
+	}
+
+	report := ImportReport{Format: format}
+	for _, item := range plan.items {
+		entry := item.entry
+		if !entry.Skipped && !opts.DryRun {
+			if err := writeImportItem(plan.destDir, item); err != nil {
+				return report, fmt.Errorf("could not import %s: %w", entry.Source, err)
+			}
+		}
+		if entry.Target != "" {
+			entry.Target = filepath.ToSlash(filepath.Join(opts.Into, entry.Target))
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+	return report, nil
+}
+
+// DetectImportFormat guesses the format of an import source: a .jex file or a
+// folder of Joplin RAW items is Joplin, a folder with an .obsidian config dir
+// is an Obsidian vault, anything else is a plain markdown folder.
+func DetectImportFormat(src string) (ImportFormat, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		if strings.EqualFold(filepath.Ext(src), ".jex") {
+			return ImportJoplin, nil
+		}
+		return "", fmt.Errorf("%s is neither a folder nor a Joplin .jex export", src)
+	}
+	if _, err := os.Stat(filepath.Join(src, ".obsidian")); err == nil {
+		return ImportObsidian, nil
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range entries {
+		if !e.IsDir() && joplinItemRegex.MatchString(e.Name()) {
+			return ImportJoplin, nil
+		}
+	}
+	return ImportMarkdown, nil
+}
+
+// add plans writing target (relative to the destination). Existing notes are
+// skipped unless overwriting, and names already taken by this import get a
+// numeric suffix.
+func (p *importPlan) add(item importItem) {
+	target := item.entry.Target
+	ext := filepath.Ext(target)
+	stem := strings.TrimSuffix(target, ext)
+	for n := 2; p.targets[target]; n++ {
+		target = fmt.Sprintf("%s-%d%s", stem, n, ext)
+	}
+	item.entry.Target = target
+	p.targets[target] = true
+
+	if !p.overwrite {
+		if _, err := os.Stat(filepath.Join(p.destDir, target)); err == nil {
+			item.entry.Skipped = true
+			item.entry.Reason = "already exists"
+		}
+	}
+	p.items = append(p.items, item)
+}
+
+// skip records a source file that is not imported.
+func (p *importPlan) skip(source, reason string) {
+	p.items = append(p.items, importItem{entry: ImportEntry{Source: source, Skipped: true, Reason: reason}})
+}
+
+func writeImportItem(destDir string, item importItem) error {
+	path := filepath.Join(destDir, item.entry.Target)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if item.content != nil {
+		return os.WriteFile(path, item.content, 0644)
+	}
+	return copyFile(item.srcPath, path)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// --- MARKDOWN FOLDERS AND OBSIDIAN VAULTS ---
+
+// planMarkdownImport copies markdown files verbatim, so front matter and tags
+// are kept, along with every other file as an attachment. The folder
+// structure is preserved; .markdown files are renamed to .md.
+func planMarkdownImport(src string, format ImportFormat, plan *importPlan) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if path == src {
+				return nil
+			}
+			// Hidden folders hold app state: .obsidian config, .trash, .git
+			if strings.HasPrefix(d.Name(), ".") {
+				plan.skip(rel+"/", "hidden folder")
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			plan.skip(rel, "not a regular file")
+			return nil
+		}
+
+		entry := ImportEntry{Source: rel, Target: rel}
+		switch strings.ToLower(filepath.Ext(d.Name())) {
+		case ".md":
+		case ".markdown":
+			entry.Target = strings.TrimSuffix(rel, filepath.Ext(rel)) + ".md"
+		case ".canvas":
+			if format == ImportObsidian {
+				plan.skip(rel, "Obsidian canvas")
+				return nil
+			}
+			entry.Attachment = true
+		default:
+			entry.Attachment = true
+		}
+		plan.add(importItem{entry: entry, srcPath: path})
+		return nil
+	})
+}
+
+// --- JOPLIN ---
+
+// Joplin RAW exports store every item (note, folder, tag, resource) as a
+// markdown file named after its 32 character id, with the metadata as
+// "key: value" lines at the end. JEX files are tar archives of the same.
+var (
+	joplinItemRegex = regexp.MustCompile(`^[0-9a-f]{32}\.md$`)
+	joplinLinkRegex = regexp.MustCompile(`\(:/([0-9a-f]{32})\)`)
+)
+
+// Joplin item types, from the type_ metadata field.
+const (
+	joplinNote     = "1"
+	joplinFolder   = "2"
+	joplinResource = "4"
+	joplinTag      = "5"
+	joplinNoteTag  = "6"
+)
+
+type joplinItem struct {
+	file  string // source file name
+	title string
+	body  string
+	meta  map[string]string
+}
+
+// parseJoplinItem splits a RAW export item into title, body and metadata.
+func parseJoplinItem(file, content string) joplinItem {
+	item := joplinItem{file: file, meta: make(map[string]string)}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	head := content
+	if idx := strings.LastIndex(content, "\n\n"); idx >= 0 {
+		head = content[:idx]
+		for _, line := range strings.Split(content[idx+2:], "\n") {
+			if key, value, ok := strings.Cut(line, ":"); ok {
+				item.meta[key] = strings.TrimSpace(value)
+			}
+		}
+	}
+	title, body, _ := strings.Cut(head, "\n")
+	item.title = strings.TrimSpace(title)
+	item.body = strings.TrimPrefix(body, "\n")
+	return item
+}
+
+func planJoplinImport(src string, plan *importPlan) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	var items []joplinItem
+	for _, e := range entries {
+		if e.IsDir() || !joplinItemRegex.MatchString(e.Name()) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(src, e.Name()))
+		if err != nil {
+			return err
+		}
+		items = append(items, parseJoplinItem(e.Name(), string(content)))
+	}
+
+	byID := make(map[string]joplinItem)
+	tagsByNote := make(map[string][]string)
+	for _, item := range items {
+		byID[item.meta["id"]] = item
+	}
+	for _, item := range items {
+		if item.meta["type_"] == joplinNoteTag {
+			if tag, ok := byID[item.meta["tag_id"]]; ok {
+				// Tags are space separated in front matter lists.
+				name := strings.ReplaceAll(tag.title, " ", "-")
+				tagsByNote[item.meta["note_id"]] = append(tagsByNote[item.meta["note_id"]], name)
+			}
+		}
+	}
+
+	// folderPath resolves the notebook hierarchy of an item.
+	var folderPath func(id string, depth int) string
+	folderPath = func(id string, depth int) string {
+		folder, ok := byID[id]
+		if !ok || folder.meta["type_"] != joplinFolder || depth > 32 {
+			return ""
+		}
+		return filepath.Join(folderPath(folder.meta["parent_id"], depth+1), importFilename(folder.title))
+	}
+
+	// Plan resources first so notes can link to their final location.
+	targets := make(map[string]string)
+	for _, item := range items {
+		if item.meta["type_"] != joplinResource {
+			continue
+		}
+		id := item.meta["id"]
+		ext := item.meta["file_extension"]
+		source := filepath.Join("resources", id)
+		if ext != "" {
+			source += "." + ext
+		}
+		if _, err := os.Stat(filepath.Join(src, source)); err != nil {
+			plan.skip(filepath.ToSlash(source), "resource file missing")
+			continue
+		}
+
+		name := importFilename(strings.TrimSuffix(item.title, filepath.Ext(item.title)))
+		if item.title == "" {
+			name = id
+		}
+		if ext != "" {
+			name += "." + ext
+		}
+		target := filepath.Join("attachments", name)
+		plan.add(importItem{
+			entry:   ImportEntry{Source: filepath.ToSlash(source), Target: filepath.ToSlash(target), Attachment: true},
+			srcPath: filepath.Join(src, source),
+		})
+		targets[id] = plan.items[len(plan.items)-1].entry.Target
+	}
+
+	// Note targets are reserved before any content is rendered so links
+	// between notes resolve regardless of order.
+	sort.Slice(items, func(i, j int) bool { return items[i].file < items[j].file })
+	for _, item := range items {
+		switch item.meta["type_"] {
+		case joplinNote:
+		case joplinFolder, joplinResource, joplinTag, joplinNoteTag:
+			continue
+		default:
+			plan.skip(item.file, "unsupported Joplin item")
+			continue
+		}
+		if item.meta["encryption_applied"] == "1" {
+			plan.skip(item.file, "encrypted in Joplin")
+			continue
+		}
+		target := filepath.Join(folderPath(item.meta["parent_id"], 0), importFilename(item.title)+".md")
+		plan.add(importItem{entry: ImportEntry{Source: item.file, Target: filepath.ToSlash(target)}})
+		targets[item.meta["id"]] = plan.items[len(plan.items)-1].entry.Target
+	}
+
+	for i := range plan.items {
+		planned := &plan.items[i]
+		if planned.entry.Attachment || planned.entry.Target == "" {
+			continue
+		}
+		note, ok := findJoplinNote(items, planned.entry.Source)
+		if !ok {
+			continue
+		}
+		noteDir := filepath.Dir(planned.entry.Target)
+		body := joplinLinkRegex.ReplaceAllStringFunc(note.body, func(link string) string {
+			target, ok := targets[joplinLinkRegex.FindStringSubmatch(link)[1]]
+			if !ok {
+				return link
+			}
+			return "(" + relLink(noteDir, target) + ")"
+		})
+		planned.content = []byte(joplinFrontMatter(note, tagsByNote[note.meta["id"]]) + body)
+	}
+	return nil
+}
+
+func findJoplinNote(items []joplinItem, file string) (joplinItem, bool) {
+	for _, item := range items {
+		if item.file == file && item.meta["type_"] == joplinNote {
+			return item, true
+		}
+	}
+	return joplinItem{}, false
+}
+
+// joplinFrontMatter renders the front matter block for an imported note.
+func joplinFrontMatter(note joplinItem, tags []string) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %q\n", note.title)
+	if len(tags) > 0 {
+		sort.Strings(tags)
+		fmt.Fprintf(&b, "tags: [%s]\n", strings.Join(tags, ", "))
+	}
+	for _, key := range []string{"created_time", "updated_time", "source_url", "author"} {
+		if value := note.meta[key]; value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", strings.TrimSuffix(key, "_time"), value)
+		}
+	}
+	b.WriteString("---\n\n")
+	return b.String()
+}
+
+// importFilename turns a note or folder title into a file name in the style
+// of notes created in GoDash, keeping non-ASCII letters.
+func importFilename(title string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r < 32 || strings.ContainsRune(`/\:*?"<>|#[]`, r):
+			return -1
+		case r == ' ':
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(title))
+	name = strings.Trim(name, ".-")
+	if name == "" {
+		return "untitled-note"
+	}
+	return name
+}
+
+// extractTar unpacks the tar archive at path into dir.
+func extractTar(path, dir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg || !filepath.IsLocal(hdr.Name) {
+			continue
+		}
+		dst := filepath.Join(dir, hdr.Name)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		out, err := os.Create(dst)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, tr); err != nil {
+			out.Close()
+			return err
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package notes
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseJoplinItem(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		wantTitle string
+		wantBody  string
+		wantMeta  map[string]string
+	}{
+		{
+			name:      "note",
+			content:   "Shopping\n\n- milk\n- bread\n\nid: 0123456789abcdef0123456789abcdef\nparent_id: fedcba9876543210fedcba9876543210\ntype_: 1",
+			wantTitle: "Shopping",
+			wantBody:  "- milk\n- bread",
+			wantMeta: map[string]string{
+				"id":        "0123456789abcdef0123456789abcdef",
+				"parent_id": "fedcba9876543210fedcba9876543210",
+				"type_":     "1",
+			},
+		},
+		{
+			name:      "folder without body",
+			content:   "Work\n\nid: fedcba9876543210fedcba9876543210\ntype_: 2",
+			wantTitle: "Work",
+			wantMeta:  map[string]string{"id": "fedcba9876543210fedcba9876543210", "type_": "2"},
+		},
+		{
+			name:      "windows line endings and colons in values",
+			content:   "Link\r\n\r\nSee https://example.com\r\n\r\nsource_url: https://example.com\r\ntype_: 1",
+			wantTitle: "Link",
+			wantBody:  "See https://example.com",
+			wantMeta:  map[string]string{"source_url": "https://example.com", "type_": "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := parseJoplinItem("item.md", tt.content)
+			if item.title != tt.wantTitle || item.body != tt.wantBody {
+				t.Errorf("parseJoplinItem() = %q, %q, want %q, %q", item.title, item.body, tt.wantTitle, tt.wantBody)
+			}
+			if !reflect.DeepEqual(item.meta, tt.wantMeta) {
+				t.Errorf("parseJoplinItem() meta = %v, want %v", item.meta, tt.wantMeta)
+			}
+		})
+	}
+}
+
+// writeTestJEX writes a Joplin .jex archive holding files.
+func writeTestJEX(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tw := tar.NewWriter(f)
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportJEX(t *testing.T) {
+	const (
+		folderID   = "10000000000000000000000000000000"
+		noteID     = "20000000000000000000000000000000"
+		otherID    = "30000000000000000000000000000000"
+		resourceID = "40000000000000000000000000000000"
+		tagID      = "50000000000000000000000000000000"
+		noteTagID  = "60000000000000000000000000000000"
+		secretID   = "70000000000000000000000000000000"
+	)
+	src := filepath.Join(t.TempDir(), "export.jex")
+	writeTestJEX(t, src, map[string]string{
+		folderID + ".md":                   "Work Stuff\n\nid: " + folderID + "\ntype_: 2",
+		noteID + ".md":                     "Plan\n\nSee [other](:/" + otherID + ") and ![chart](:/" + resourceID + ")\n\nid: " + noteID + "\nparent_id: " + folderID + "\ncreated_time: 2024-01-02T03:04:05.000Z\ntype_: 1",
+		otherID + ".md":                    "Other note\n\nHello\n\nid: " + otherID + "\ntype_: 1",
+		resourceID + ".md":                 "chart.png\n\nid: " + resourceID + "\nfile_extension: png\ntype_: 4",
+		tagID + ".md":                      "big project\n\nid: " + tagID + "\ntype_: 5",
+		noteTagID + ".md":                  "\n\nid: " + noteTagID + "\nnote_id: " + noteID + "\ntag_id: " + tagID + "\ntype_: 6",
+		secretID + ".md":                   "Secret\n\nid: " + secretID + "\nencryption_applied: 1\ntype_: 1",
+		"resources/" + resourceID + ".png": "png",
+	})
+	notesDir := t.TempDir()
+
+	report, err := importInto(src, notesDir, ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Format != ImportJoplin {
+		t.Errorf("format = %q, want %q", report.Format, ImportJoplin)
+	}
+	notes, attachments, skipped := report.Counts()
+	if notes != 2 || attachments != 1 || skipped != 1 {
+		t.Errorf("Counts() = %d, %d, %d, want 2, 1, 1", notes, attachments, skipped)
+	}
+
+	files := map[string]string{
+		"Work-Stuff/Plan.md":    "---\ntitle: \"Plan\"\ntags: [big-project]\ncreated: 2024-01-02T03:04:05.000Z\n---\n\nSee [other](../Other-note.md) and ![chart](../attachments/chart.png)",
+		"Other-note.md":         "---\ntitle: \"Other note\"\n---\n\nHello",
+		"attachments/chart.png": "png",
+	}
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join(notesDir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ package notes
 import (
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -21,6 +22,16 @@ import (
 type EditNoteMsg struct {
 	Path    string
 	Content []byte
+	// Encrypted is set when Content is ciphertext that must be decrypted
+	// before editing.
+	Encrypted bool
+}
+
+// ToggleEncryptionMsg is sent when a note should be encrypted, or have its
+// encryption removed if it is already encrypted.
+type ToggleEncryptionMsg struct {
+	Path      string
+	Encrypted bool
 }
 
 type NoteState int
@@ -28,12 +39,14 @@ type NoteState int
 const (
 	NoteStateList NoteState = iota
 	NoteStateCreate
+	NoteStateChecklist
 )
 
 // note represents a single note in the list.
 type note struct {
-	title string
-	path  string
+	title     string
+	path      string
+	encrypted bool
 }
 
 // These methods implement the list.Item interface.
@@ -53,6 +66,9 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	}
 
 	str := n.title
+	if n.encrypted {
+		str = "🔒 " + str
+	}
 	// Render selected state
 	if index == m.Index() {
 		fmt.Fprint(w, lipgloss.NewStyle().PaddingLeft(0).Foreground(lipgloss.Color("#56b6c2")).Render("> "+str))
@@ -63,15 +79,19 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 }
 
 var (
-	noteBoxStyle = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(1, 2)
+	noteBoxStyle         = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(1, 2)
+	checklistStatusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#e06c75"))
 )
 
 type Model struct {
 	List         list.Model
+	Checklist    list.Model
 	TextInput    textinput.Model
 	State        NoteState
 	keys         KeyMap
 	width, height int
+	// checklistStatus reports the last checklist error, if any.
+	checklistStatus string
 }
 
 type KeyMap struct {
@@ -81,6 +101,12 @@ type KeyMap struct {
 	SaveNote   key.Binding
 	Confirm    key.Binding
 	Cancel     key.Binding
+
+	ShowChecklist key.Binding
+	ToggleItem    key.Binding
+	PromoteItem   key.Binding
+
+	ToggleEncryption key.Binding
 }
 
 func New(keys KeyMap) Model {
@@ -107,6 +133,7 @@ func New(keys KeyMap) Model {
 
 	return Model{
 		List:           l,
+		Checklist:      newChecklistList(),
 		TextInput:      ti,
 		State:          NoteStateList,
 		keys:           keys,
@@ -142,7 +169,7 @@ func loadNotes() ([]note, error) {
 
 	noteCount := 0
 	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".md") {
+		if !file.IsDir() && isNoteFile(file.Name()) {
 			noteCount++
 		}
 	}
@@ -165,20 +192,38 @@ func loadNotes() ([]note, error) {
 		}
 	}
 
+	// Notes in subfolders (e.g. imported vaults) are listed as "Folder/Title".
 	var notes []note
-	re := regexp.MustCompile(`^\d+\s`)
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".md") {
-			title := strings.TrimSuffix(file.Name(), ".md")
-			title = strings.ReplaceAll(title, "-", " ") // Replace hyphens with spaces for display
-			title = re.ReplaceAllString(title, "")      // Strip numerical prefix
-			notes = append(notes, note{
-				title: title,
-				path:  filepath.Join(notesDir, file.Name()),
-			})
+	err = filepath.WalkDir(notesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
 		}
-	}
-	return notes, nil
+		if d.IsDir() {
+			if path != notesDir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "attachments") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isNoteFile(d.Name()) {
+			return nil
+		}
+		title := titleFromFilename(d.Name())
+		if rel, err := filepath.Rel(notesDir, filepath.Dir(path)); err == nil && rel != "." {
+			title = filepath.ToSlash(rel) + "/" + title
+		}
+		notes = append(notes, note{
+			title:     title,
+			path:      path,
+			encrypted: IsEncryptedPath(d.Name()),
+		})
+		return nil
+	})
+	return notes, err
+}
+
+// isNoteFile reports whether name is a plain or encrypted markdown note.
+func isNoteFile(name string) bool {
+	return strings.HasSuffix(name, ".md") || IsEncryptedPath(name)
 }
 
 func createDefaultNotes(dir string) {
@@ -370,6 +415,40 @@ func (m *Model) Update(msg tea.Msg, focused bool) (Model, tea.Cmd) {
 			m.TextInput, cmd = m.TextInput.Update(msg)
 			cmds = append(cmds, cmd)
 
+		case NoteStateChecklist:
+			switch msg := msg.(type) {
+			case tea.KeyMsg:
+				if m.Checklist.FilterState() == list.Filtering {
+					break
+				}
+				if m.checklistStatus != "" {
+					m.setChecklistStatus("")
+				}
+				switch {
+				case key.Matches(msg, m.keys.Cancel):
+					m.State = NoteStateList
+					return *m, nil
+				case key.Matches(msg, m.keys.ToggleItem):
+					if selected, ok := m.Checklist.SelectedItem().(ChecklistItem); ok {
+						err := ToggleChecklistItem(selected)
+						m.loadChecklist()
+						if err != nil {
+							m.setChecklistStatus("Error toggling checklist item: " + err.Error())
+						}
+					}
+					return *m, nil
+				case key.Matches(msg, m.keys.PromoteItem):
+					if selected, ok := m.Checklist.SelectedItem().(ChecklistItem); ok {
+						promoteCmd := func() tea.Msg {
+							return PromoteToTodoMsg{Title: selected.Text}
+						}
+						return *m, promoteCmd
+					}
+				}
+			}
+			m.Checklist, cmd = m.Checklist.Update(msg)
+			cmds = append(cmds, cmd)
+
 		case NoteStateList:
 			switch msg := msg.(type) {
 			case tea.KeyMsg:
@@ -377,6 +456,10 @@ func (m *Model) Update(msg tea.Msg, focused bool) (Model, tea.Cmd) {
 					break
 				}
 				switch {
+				case key.Matches(msg, m.keys.ShowChecklist):
+					m.State = NoteStateChecklist
+					m.loadChecklist()
+					return *m, nil
 				case key.Matches(msg, m.keys.CreateNote):
 					m.State = NoteStateCreate
 					m.TextInput.Focus()
@@ -388,6 +471,13 @@ func (m *Model) Update(msg tea.Msg, focused bool) (Model, tea.Cmd) {
 							m.List.RemoveItem(m.List.Index())
 						}
 					}
+				case key.Matches(msg, m.keys.ToggleEncryption):
+					if selected, ok := m.List.SelectedItem().(note); ok {
+						encryptCmd := func() tea.Msg {
+							return ToggleEncryptionMsg{Path: selected.path, Encrypted: selected.encrypted}
+						}
+						return *m, encryptCmd
+					}
 				case key.Matches(msg, m.keys.Confirm): // Enter key
 					if selected, ok := m.List.SelectedItem().(note); ok {
 						content, err := os.ReadFile(selected.path)
@@ -396,7 +486,7 @@ func (m *Model) Update(msg tea.Msg, focused bool) (Model, tea.Cmd) {
 							content = []byte("Could not read file: " + err.Error())
 						}
 						editCmd := func() tea.Msg {
-							return EditNoteMsg{Path: selected.path, Content: content}
+							return EditNoteMsg{Path: selected.path, Content: content, Encrypted: selected.encrypted && err == nil}
 						}
 						return *m, editCmd
 					}
@@ -414,6 +504,11 @@ func (m *Model) View() string {
 	switch m.State {
 	case NoteStateCreate:
 		return lipgloss.JoinVertical(lipgloss.Left, m.List.View(), m.TextInput.View())
+	case NoteStateChecklist:
+		if m.checklistStatus != "" {
+			return lipgloss.JoinVertical(lipgloss.Left, m.Checklist.View(), m.checklistStatusView())
+		}
+		return m.Checklist.View()
 	default: // NoteStateList
 		return m.List.View()
 	}
@@ -424,6 +519,7 @@ func (m *Model) SetSize(width, height int) {
 	m.height = height
 
 	m.List.SetSize(width, height)
+	m.resizeChecklist()
 	m.TextInput.Width = width
 
 	if m.State == NoteStateCreate {
@@ -0,0 +1,41 @@
+package notes
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Heading is a markdown ATX heading found in a note.
+type Heading struct {
+	Level int
+	Text  string
+	Line  int // zero-based line number in the note
+}
+
+var atxHeadingRegex = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+
+// ParseOutline returns the heading structure of content, skipping fenced code
+// blocks where "#" usually starts a comment.
+func ParseOutline(content string) []Heading {
+	var headings []Heading
+	inFence := false
+	for i, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		match := atxHeadingRegex.FindStringSubmatch(line)
+		if match == nil || strings.TrimSpace(match[2]) == "" {
+			continue
+		}
+		headings = append(headings, Heading{
+			Level: len(match[1]),
+			Text:  strings.TrimSpace(match[2]),
+			Line:  i,
+		})
+	}
+	return headings
+}
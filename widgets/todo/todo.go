@@ -204,6 +204,12 @@ func (m *Model) GetState() ListState {
 	return m.State
 }
 
+// AddTask appends a new task with the given title and saves the list.
+func (m *Model) AddTask(title string) {
+	m.List.InsertItem(len(m.List.Items()), task{Title: title})
+	m.saveTasks()
+}
+
 func (m *Model) saveTasks() {
 	saveTasks(m.path, m.List.Items())
 }